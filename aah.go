@@ -39,6 +39,8 @@ var (
 	appIsSSLEnabled       bool
 	appIsLetsEncrypt      bool
 	appMultipartMaxMemory int64
+	appMaxBodyBytes       int64
+	appMultipartBodyBytes int64
 	appPID                int
 	appInitialized        bool
 	appBuildInfo          *BuildInfo
@@ -196,8 +198,17 @@ func Init(importPath string) {
 
 		logAsFatal(initAppVariables())
 		logAsFatal(initLogs(appLogsDir(), AppConfig()))
+		logAsFatal(initAccessLog(appLogsDir(), AppConfig()))
 		logAsFatal(initI18n(appI18nDir()))
 		logAsFatal(initRoutes(appConfigDir(), AppConfig()))
+		logAsFatal(initSanitizer(AppConfig()))
+		logAsFatal(initResponseCache(AppConfig()))
+		logAsFatal(initJSONOptions(AppConfig()))
+		logAsFatal(initRequestTimeout(AppConfig()))
+		logAsFatal(initMetrics(AppConfig()))
+		logAsFatal(initHealth(AppConfig()))
+		logAsFatal(initTracing(AppConfig()))
+		logAsFatal(initSlowRequest(AppConfig()))
 		logAsFatal(initSecurity(appConfigDir(), AppConfig()))
 		logAsFatal(initViewEngine(appViewsDir(), AppConfig()))
 	}
@@ -299,6 +310,16 @@ func initAppVariables() error {
 		return errors.New("'request.multipart_size' value is not a valid size unit")
 	}
 
+	maxBodySizeStr := cfg.StringDefault("request.max_body_size", "5mb")
+	if appMaxBodyBytes, err = ess.StrToBytes(maxBodySizeStr); err != nil {
+		return errors.New("'request.max_body_size' value is not a valid size unit")
+	}
+
+	multipartBodySizeStr := cfg.StringDefault("request.multipart.max_body_size", "32mb")
+	if appMultipartBodyBytes, err = ess.StrToBytes(multipartBodySizeStr); err != nil {
+		return errors.New("'request.multipart.max_body_size' value is not a valid size unit")
+	}
+
 	return nil
 }
 
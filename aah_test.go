@@ -44,6 +44,8 @@ func TestAahInitAppVariables(t *testing.T) {
 	assert.Equal(t, 1048576, appHTTPMaxHdrBytes)
 	assert.False(t, appInitialized)
 	assert.Equal(t, int64(33554432), appMultipartMaxMemory)
+	assert.Equal(t, int64(5242880), appMaxBodyBytes)
+	assert.Equal(t, int64(33554432), appMultipartBodyBytes)
 	assert.True(t, ess.IsStrEmpty(appSSLCert))
 	assert.True(t, ess.IsStrEmpty(appSSLKey))
 
@@ -119,6 +121,17 @@ func TestAahInitAppVariables(t *testing.T) {
 	assert.Equal(t, "'request.multipart_size' value is not a valid size unit", err.Error())
 	AppConfig().SetString("request.multipart_size", "12mb")
 
+	// max body size parsing error
+	AppConfig().SetString("request.max_body_size", "2sb")
+	err = initAppVariables()
+	assert.Equal(t, "'request.max_body_size' value is not a valid size unit", err.Error())
+	AppConfig().SetString("request.max_body_size", "5mb")
+
+	AppConfig().SetString("request.multipart.max_body_size", "2sb")
+	err = initAppVariables()
+	assert.Equal(t, "'request.multipart.max_body_size' value is not a valid size unit", err.Error())
+	AppConfig().SetString("request.multipart.max_body_size", "32mb")
+
 	SetAppPackaged(true)
 	assert.True(t, appIsPackaged)
 
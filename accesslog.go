@@ -0,0 +1,376 @@
+// Copyright (c) Jeevanandam M. (https://github.com/jeevatkm)
+// go-aah/aah source code and usage is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package aah
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"path/filepath"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"aahframework.org/config.v0"
+	"aahframework.org/essentials.v0"
+	"aahframework.org/log.v0"
+)
+
+const (
+	accessLogFormatText = "text"
+	accessLogFormatJSON = "json"
+	accessLogEmptyValue = "-"
+
+	defaultAccessLogPattern    = "%clientip %reqid %reqtime %restime %resstatus %ressize %reqmethod %requrl"
+	defaultAccessLogTimeFormat = "2006-01-02 15:04:05.000"
+)
+
+var (
+	appAccessLogReceiver accessLogReceiver
+	appAccessLogChan     chan *accessLog
+	appAccessLogDone     chan struct{}
+	appAccessLogMu       = &sync.RWMutex{}
+	appAccessLogDropped  uint64
+	appAccessLogFlags    []*accessLogFlag
+	appAccessLogFormat   = accessLogFormatText
+	appAccessLogUserKey  = "Username"
+
+	accessLogFlagValues = map[string]func(al *accessLog, arg string) interface{}{
+		"clientip":   func(al *accessLog, arg string) interface{} { return al.ClientIP },
+		"reqid":      func(al *accessLog, arg string) interface{} { return al.RequestID },
+		"reqtime":    func(al *accessLog, arg string) interface{} { return al.StartTime.Format(arg) },
+		"restime":    func(al *accessLog, arg string) interface{} { return al.Latency },
+		"resstatus":  func(al *accessLog, arg string) interface{} { return al.Status },
+		"ressize":    func(al *accessLog, arg string) interface{} { return al.Bytes },
+		"reqmethod":  func(al *accessLog, arg string) interface{} { return al.Method },
+		"requrl":     func(al *accessLog, arg string) interface{} { return al.Path },
+		"reqproto":   func(al *accessLog, arg string) interface{} { return al.Proto },
+		"querystr":   func(al *accessLog, arg string) interface{} { return al.Query },
+		"useragent":  func(al *accessLog, arg string) interface{} { return al.UserAgent },
+		"referer":    func(al *accessLog, arg string) interface{} { return al.Referer },
+		"user":       func(al *accessLog, arg string) interface{} { return al.User },
+		"reqhdr":     func(al *accessLog, arg string) interface{} { return al.ReqHdr.Get(arg) },
+		"reshdr":     func(al *accessLog, arg string) interface{} { return al.ResHdr.Get(arg) },
+		"controller": func(al *accessLog, arg string) interface{} { return al.Controller },
+	}
+)
+
+type (
+	// accessLogReceiver is the writer of formatted access log entry, it's
+	// satisfied by `log.Logger`.
+	accessLogReceiver interface {
+		Info(v ...interface{})
+	}
+
+	// accessLogFlag is parsed part of access log pattern, it's either
+	// literal text or flag with optional argument.
+	accessLogFlag struct {
+		Name    string
+		Arg     string
+		Literal string
+	}
+
+	// accessLog holds the request and response details of access log entry,
+	// it's captured from the request context once reply is written.
+	accessLog struct {
+		StartTime  time.Time
+		Latency    time.Duration
+		ClientIP   string
+		RequestID  string
+		Method     string
+		Path       string
+		Proto      string
+		Query      string
+		UserAgent  string
+		Referer    string
+		User       string
+		Controller string
+		Status     int
+		Bytes      int
+		ReqHdr     http.Header
+		ResHdr     http.Header
+	}
+)
+
+//‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾
+// accessLog methods
+//___________________________________
+
+// FormatText method returns the access log entry as per pattern flags,
+// empty value is written as `-`.
+func (al *accessLog) FormatText(flags []*accessLogFlag) string {
+	buf := &bytes.Buffer{}
+	for _, flag := range flags {
+		if ess.IsStrEmpty(flag.Name) {
+			buf.WriteString(flag.Literal)
+			continue
+		}
+
+		value := fmt.Sprintf("%v", accessLogFlagValues[flag.Name](al, flag.Arg))
+		if ess.IsStrEmpty(value) {
+			value = accessLogEmptyValue
+		}
+		buf.WriteString(value)
+	}
+	return buf.String()
+}
+
+// FormatJSON method returns the access log entry as JSON object with pattern
+// flags as keys in the pattern order, literal text is not applicable.
+// Response time `restime` is in milliseconds.
+func (al *accessLog) FormatJSON(flags []*accessLogFlag) string {
+	buf := &bytes.Buffer{}
+	buf.WriteByte('{')
+	for _, flag := range flags {
+		if ess.IsStrEmpty(flag.Name) {
+			continue
+		}
+
+		value := accessLogFlagValues[flag.Name](al, flag.Arg)
+		if latency, ok := value.(time.Duration); ok {
+			value = float64(latency) / float64(time.Millisecond)
+		}
+
+		key := flag.Name
+		if !ess.IsStrEmpty(flag.Arg) && flag.Name != "reqtime" {
+			key += ":" + flag.Arg
+		}
+
+		if buf.Len() > 1 {
+			buf.WriteByte(',')
+		}
+		k, _ := json.Marshal(key)
+		v, _ := json.Marshal(value)
+		buf.Write(k)
+		buf.WriteByte(':')
+		buf.Write(v)
+	}
+	buf.WriteByte('}')
+	return buf.String()
+}
+
+//‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾
+// Unexported methods
+//___________________________________
+
+// sendToAccessLog method captures the access log entry from the request
+// context and sends it to the access log channel, it's written by the
+// listener asynchronously. Entry is dropped if the channel buffer is full,
+// so request is never blocked by slow access log receiver.
+func sendToAccessLog(ctx *Context) {
+	appAccessLogMu.RLock()
+	defer appAccessLogMu.RUnlock()
+	if appAccessLogChan == nil {
+		return
+	}
+
+	// timed out request is logged by the timeout reply
+	if tw, ok := ctx.Res.(*timeoutWriter); ok && tw.isTimedOut() {
+		return
+	}
+
+	req := ctx.Req
+	al := &accessLog{
+		StartTime:  ctx.startTime,
+		Latency:    time.Since(ctx.startTime),
+		ClientIP:   req.ClientIP,
+		RequestID:  req.Header.Get(appRequestIDHeader),
+		Method:     req.Method,
+		Path:       req.Path,
+		Proto:      req.Raw.Proto,
+		Query:      req.Raw.URL.RawQuery,
+		UserAgent:  req.Raw.UserAgent(),
+		Referer:    req.Raw.Referer(),
+		Controller: ctx.controller,
+		Status:     ctx.Reply().Code,
+		ReqHdr:     http.Header{},
+		ResHdr:     http.Header{},
+	}
+
+	if ctx.action != nil {
+		al.Controller += "." + ctx.action.Name
+	}
+
+	if ctx.Res != nil && ctx.Res.Status() > 0 {
+		al.Status = ctx.Res.Status()
+		al.Bytes = ctx.Res.BytesWritten()
+	}
+
+	if ctx.session != nil {
+		al.User = ctx.session.GetString(appAccessLogUserKey)
+	}
+
+	for _, flag := range appAccessLogFlags {
+		switch flag.Name {
+		case "reqhdr":
+			al.ReqHdr[http.CanonicalHeaderKey(flag.Arg)] = req.Header[http.CanonicalHeaderKey(flag.Arg)]
+		case "reshdr":
+			if ctx.Res != nil {
+				al.ResHdr[http.CanonicalHeaderKey(flag.Arg)] = ctx.Res.Header()[http.CanonicalHeaderKey(flag.Arg)]
+			}
+		}
+	}
+
+	select {
+	case appAccessLogChan <- al:
+	default:
+		atomic.AddUint64(&appAccessLogDropped, 1)
+	}
+}
+
+// listenForAccessLog method writes the access log entries from the channel
+// until it's closed.
+func listenForAccessLog(ch <-chan *accessLog, done chan<- struct{}) {
+	for al := range ch {
+		if appAccessLogFormat == accessLogFormatJSON {
+			appAccessLogReceiver.Info(al.FormatJSON(appAccessLogFlags))
+		} else {
+			appAccessLogReceiver.Info(al.FormatText(appAccessLogFlags))
+		}
+	}
+	close(done)
+}
+
+// closeAccessLog method closes the access log channel and waits for the
+// buffered entries to be written. Channel is closed under the write lock,
+// so in-flight sends are completed and later ones are no-op.
+func closeAccessLog() {
+	appAccessLogMu.Lock()
+	if appAccessLogChan == nil {
+		appAccessLogMu.Unlock()
+		return
+	}
+	close(appAccessLogChan)
+	appAccessLogChan = nil
+	appAccessLogMu.Unlock()
+
+	<-appAccessLogDone
+	if dropped := atomic.SwapUint64(&appAccessLogDropped, 0); dropped > 0 {
+		log.Warnf("Access log dropped %d entries, consider increasing 'server.access_log.buffer_size'", dropped)
+	}
+}
+
+// parseAccessLogPattern method parses the access log pattern into literal
+// text and flags. Flag is `%name` with optional argument `%name:arg`, for
+// e.g. `%reqhdr:X-Forwarded-For`.
+func parseAccessLogPattern(pattern string) ([]*accessLogFlag, error) {
+	var (
+		flags   []*accessLogFlag
+		literal bytes.Buffer
+	)
+
+	for idx := 0; idx < len(pattern); idx++ {
+		if pattern[idx] != '%' {
+			literal.WriteByte(pattern[idx])
+			continue
+		}
+
+		end := idx + 1
+		for end < len(pattern) && isAccessLogFlagChar(pattern[end]) {
+			end++
+		}
+
+		flag := &accessLogFlag{Name: pattern[idx+1 : end]}
+		if _, found := accessLogFlagValues[flag.Name]; !found {
+			return nil, fmt.Errorf("access log: unknown pattern flag '%%%s'", flag.Name)
+		}
+
+		if end < len(pattern) && pattern[end] == ':' {
+			argEnd := end + 1
+			for argEnd < len(pattern) && !strings.ContainsRune(" \t\"'[]", rune(pattern[argEnd])) {
+				argEnd++
+			}
+			flag.Arg = pattern[end+1 : argEnd]
+			end = argEnd
+		}
+
+		if (flag.Name == "reqhdr" || flag.Name == "reshdr") && ess.IsStrEmpty(flag.Arg) {
+			return nil, fmt.Errorf("access log: header name is required for '%%%s'", flag.Name)
+		}
+
+		if flag.Name == "reqtime" && ess.IsStrEmpty(flag.Arg) {
+			flag.Arg = defaultAccessLogTimeFormat
+		}
+
+		if literal.Len() > 0 {
+			flags = append(flags, &accessLogFlag{Literal: literal.String()})
+			literal.Reset()
+		}
+		flags = append(flags, flag)
+		idx = end - 1
+	}
+
+	if literal.Len() > 0 {
+		flags = append(flags, &accessLogFlag{Literal: literal.String()})
+	}
+	return flags, nil
+}
+
+// isAccessLogFlagChar method returns true if given char is valid for flag name.
+func isAccessLogFlagChar(c byte) bool {
+	return c >= 'a' && c <= 'z'
+}
+
+// initAccessLog method initializes the request access log from aah.conf
+// `server.access_log`. Receiver `file` and `console` are created via aah
+// log library, so file rotation is supported as per `rotate` config.
+func initAccessLog(logsDir string, appCfg *config.Config) error {
+	if !appCfg.BoolDefault("server.access_log.enable", false) {
+		return nil
+	}
+
+	flags, err := parseAccessLogPattern(appCfg.StringDefault("server.access_log.pattern", defaultAccessLogPattern))
+	if err != nil {
+		return err
+	}
+
+	format := appCfg.StringDefault("server.access_log.format", accessLogFormatText)
+	if format != accessLogFormatText && format != accessLogFormatJSON {
+		return fmt.Errorf("'server.access_log.format' value '%s' is not supported", format)
+	}
+
+	if appAccessLogReceiver == nil {
+		logCfg, _ := config.ParseString("")
+		receiver := appCfg.StringDefault("server.access_log.receiver", "file")
+		logCfg.SetString("log.receiver", receiver)
+		logCfg.SetString("log.level", "info")
+		logCfg.SetString("log.pattern", "%message")
+		if receiver == "file" {
+			file := appCfg.StringDefault("server.access_log.file", "")
+			if ess.IsStrEmpty(file) {
+				file = getBinaryFileName() + "-access.log"
+			}
+			if !filepath.IsAbs(file) {
+				file = filepath.Join(logsDir, file)
+			}
+			logCfg.SetString("log.file", file)
+
+			logCfg.SetString("log.rotate.policy", appCfg.StringDefault("server.access_log.rotate.policy", "daily"))
+			logCfg.SetInt("log.rotate.lines", appCfg.IntDefault("server.access_log.rotate.lines", 0))
+			logCfg.SetInt("log.rotate.size", appCfg.IntDefault("server.access_log.rotate.size", 0))
+		}
+
+		logger, err := log.New(logCfg)
+		if err != nil {
+			return fmt.Errorf("access log: %s", err)
+		}
+		appAccessLogReceiver = logger
+	}
+
+	appAccessLogFlags = flags
+	appAccessLogFormat = format
+	appAccessLogUserKey = appCfg.StringDefault("server.access_log.session_user_key", "Username")
+
+	closeAccessLog()
+	appAccessLogMu.Lock()
+	appAccessLogChan = make(chan *accessLog, appCfg.IntDefault("server.access_log.buffer_size", 500))
+	appAccessLogDone = make(chan struct{})
+	go listenForAccessLog(appAccessLogChan, appAccessLogDone)
+	appAccessLogMu.Unlock()
+
+	return nil
+}
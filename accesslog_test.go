@@ -0,0 +1,164 @@
+// Copyright (c) Jeevanandam M. (https://github.com/jeevatkm)
+// go-aah/aah source code and usage is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package aah
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"aahframework.org/config.v0"
+	"aahframework.org/test.v0/assert"
+)
+
+type testAccessLogReceiver struct {
+	lines []string
+}
+
+func (r *testAccessLogReceiver) Info(v ...interface{}) {
+	r.lines = append(r.lines, fmt.Sprint(v...))
+}
+
+func TestAccessLogParsePattern(t *testing.T) {
+	flags, err := parseAccessLogPattern(defaultAccessLogPattern)
+	assert.Nil(t, err)
+	assert.Equal(t, 15, len(flags))
+	assert.Equal(t, "clientip", flags[0].Name)
+	assert.Equal(t, " ", flags[1].Literal)
+	assert.Equal(t, defaultAccessLogTimeFormat, flags[4].Arg)
+
+	flags, err = parseAccessLogPattern(`[%reqtime:2006-01-02] "%reqmethod %requrl" %reqhdr:X-Forwarded-For`)
+	assert.Nil(t, err)
+	assert.Equal(t, 8, len(flags))
+	assert.Equal(t, &accessLogFlag{Literal: "["}, flags[0])
+	assert.Equal(t, &accessLogFlag{Name: "reqtime", Arg: "2006-01-02"}, flags[1])
+	assert.Equal(t, &accessLogFlag{Literal: `] "`}, flags[2])
+	assert.Equal(t, &accessLogFlag{Name: "reqhdr", Arg: "X-Forwarded-For"}, flags[7])
+
+	// errors
+	_, err = parseAccessLogPattern("%clientip %unknown")
+	assert.Equal(t, "access log: unknown pattern flag '%unknown'", err.Error())
+
+	_, err = parseAccessLogPattern("%reshdr %requrl")
+	assert.Equal(t, "access log: header name is required for '%reshdr'", err.Error())
+}
+
+func TestAccessLogFormat(t *testing.T) {
+	al := &accessLog{
+		StartTime: time.Date(2017, 7, 21, 10, 30, 15, 0, time.UTC),
+		Latency:   1500 * time.Microsecond,
+		ClientIP:  "10.0.0.1",
+		Method:    "GET",
+		Path:      "/users",
+		Status:    200,
+		Bytes:     512,
+		ReqHdr:    http.Header{"X-Forwarded-For": []string{"192.168.0.1"}},
+		ResHdr:    http.Header{},
+	}
+
+	flags, err := parseAccessLogPattern("%clientip %user [%reqtime] %reqmethod %requrl %resstatus %ressize %restime %reqhdr:X-Forwarded-For")
+	assert.FailNowOnError(t, err, "")
+
+	assert.Equal(t, "10.0.0.1 - [2017-07-21 10:30:15.000] GET /users 200 512 1.5ms 192.168.0.1",
+		al.FormatText(flags))
+	assert.Equal(t, `{"clientip":"10.0.0.1","user":"","reqtime":"2017-07-21 10:30:15.000","reqmethod":"GET",`+
+		`"requrl":"/users","resstatus":200,"ressize":512,"restime":1.5,"reqhdr:X-Forwarded-For":"192.168.0.1"}`,
+		al.FormatJSON(flags))
+}
+
+func TestAccessLogInit(t *testing.T) {
+	defer func() {
+		closeAccessLog()
+		appAccessLogReceiver, appAccessLogFlags, appAccessLogFormat = nil, nil, accessLogFormatText
+	}()
+
+	cfg, _ := config.ParseString("")
+	assert.Nil(t, initAccessLog("logs", cfg))
+	assert.Nil(t, appAccessLogChan)
+
+	// not enabled, no-op
+	sendToAccessLog(&Context{})
+
+	cfg.SetBool("server.access_log.enable", true)
+	cfg.SetString("server.access_log.format", "xml")
+	assert.Equal(t, "'server.access_log.format' value 'xml' is not supported", initAccessLog("logs", cfg).Error())
+
+	cfg.SetString("server.access_log.format", "text")
+	cfg.SetString("server.access_log.pattern", "%clientip %bytes")
+	assert.Equal(t, "access log: unknown pattern flag '%bytes'", initAccessLog("logs", cfg).Error())
+
+	receiver := &testAccessLogReceiver{}
+	appAccessLogReceiver = receiver
+	cfg.SetString("server.access_log.pattern", "%reqmethod %requrl %resstatus %ressize %reqhdr:X-Forwarded-For %reshdr:Content-Type")
+	assert.Nil(t, initAccessLog("logs", cfg))
+	assert.NotNil(t, appAccessLogChan)
+
+	// reply
+	err := initSecurity(filepath.Join(getTestdataPath(), appConfigDir()), cfg)
+	assert.FailNowOnError(t, err, "")
+	e := newEngine(cfg)
+	r := httptest.NewRequest("POST", "http://localhost:8080/users", nil)
+	r.Header.Set("X-Forwarded-For", "192.168.0.1")
+	ctx := e.prepareContext(httptest.NewRecorder(), r)
+	ctx.Reply().Created().Text("created")
+	e.writeReply(ctx)
+
+	// redirect
+	ctx = e.prepareContext(httptest.NewRecorder(), httptest.NewRequest("GET", "http://localhost:8080/home", nil))
+	ctx.Reply().Redirect("/")
+	e.writeReply(ctx)
+
+	// static file
+	testStaticServe(t, e, "http://localhost:8080/robots.txt", "", "", "test.txt", "This is file content of test.txt", false)
+
+	closeAccessLog()
+	assert.Nil(t, appAccessLogChan)
+	assert.Equal(t, []string{
+		"POST /users 201 7 192.168.0.1 text/plain; charset=utf-8",
+		"GET /home 302 24 - text/html; charset=utf-8",
+		"GET /robots.txt 200 33 - text/plain; charset=utf-8",
+	}, receiver.lines)
+}
+
+func TestAccessLogDropAndClose(t *testing.T) {
+	defer func() {
+		appAccessLogChan, appAccessLogDropped = nil, 0
+		appAccessLogReceiver, appAccessLogFlags = nil, nil
+	}()
+
+	appConfig, _ = config.ParseString("")
+	err := initSecurity(filepath.Join(getTestdataPath(), appConfigDir()), appConfig)
+	assert.FailNowOnError(t, err, "")
+	e := newEngine(appConfig)
+	ctx := e.prepareContext(httptest.NewRecorder(), httptest.NewRequest("GET", "http://localhost:8080/users", nil))
+
+	// full buffer, listener is not running
+	appAccessLogChan = make(chan *accessLog, 1)
+	appAccessLogDone = make(chan struct{})
+	done := make(chan bool)
+	go func() {
+		sendToAccessLog(ctx)
+		sendToAccessLog(ctx)
+		sendToAccessLog(ctx)
+		done <- true
+	}()
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("access log send is blocked")
+	}
+	assert.Equal(t, uint64(2), appAccessLogDropped)
+
+	// send after close is no-op
+	appAccessLogReceiver = &testAccessLogReceiver{}
+	go listenForAccessLog(appAccessLogChan, appAccessLogDone)
+	closeAccessLog()
+	assert.Equal(t, uint64(0), appAccessLogDropped)
+	sendToAccessLog(ctx)
+	assert.Equal(t, 1, len(appAccessLogReceiver.(*testAccessLogReceiver).lines))
+}
@@ -0,0 +1,592 @@
+// Copyright (c) Jeevanandam M. (https://github.com/jeevatkm)
+// go-aah/aah source code and usage is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package aah
+
+import (
+	"bytes"
+	"encoding/json"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"net/url"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"aahframework.org/ahttp.v0"
+	"aahframework.org/essentials.v0"
+)
+
+const (
+	keyBindTag = "bind"
+)
+
+var (
+	errEmptyPayload = errors.New("request payload is empty")
+
+	timeType        = reflect.TypeOf(time.Time{})
+	fileHeaderType  = reflect.TypeOf((*multipart.FileHeader)(nil))
+	fileHeadersType = reflect.TypeOf(([]*multipart.FileHeader)(nil))
+
+	valueParsers = make(map[reflect.Type]ValueParser)
+)
+
+type (
+	// ValueParser func type is aah framework action parameter value parser
+	// signature. It returns the value of given type for the given key from
+	// request params.
+	ValueParser func(key string, typ reflect.Type, params *ahttp.Params) (reflect.Value, error)
+
+	// BindError holds the details of request value binding failure such as
+	// offending field, value and the cause. Framework replies HTTP Bad Request
+	// (Status 400) for the bind error, `HTTPError.Data` holds the bind error.
+	BindError struct {
+		Field   string `json:"field,omitempty" xml:"field,omitempty"`
+		Value   string `json:"value,omitempty" xml:"value,omitempty"`
+		Message string `json:"message" xml:"message"`
+		Err     error  `json:"-" xml:"-"`
+	}
+
+	// valueParserError is the misconfiguration of registered value parser,
+	// i.e. returned value is not assignable to the type. It's the server
+	// error, not the bad request.
+	valueParserError struct {
+		returned reflect.Type
+		expected reflect.Type
+	}
+
+	// bindTargetError is the unsupported type of given value for binding,
+	// it's the server error, not the bad request.
+	bindTargetError struct {
+		typ reflect.Type
+	}
+)
+
+//‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾
+// Global methods
+//___________________________________
+
+// AddValueParser method adds given custom value parser for the given type
+// into parser registry. Value parser is consulted before framework built-in
+// types while binding action parameters, struct fields and pointer types.
+//
+// Slice of custom type is bound element by element with the value parser of
+// element type, each value is made available via given params for the key.
+// Value parser registered for slice type takes precedence.
+//		For Example:
+//
+//		aah.AddValueParser(reflect.TypeOf(uuid.UUID{}), func(key string,
+//			typ reflect.Type, params *ahttp.Params) (reflect.Value, error) {
+//			id, err := uuid.FromString(params.QueryValue(key))
+//			return reflect.ValueOf(id), err
+//		})
+func AddValueParser(typ reflect.Type, parser ValueParser) error {
+	if typ == nil || parser == nil {
+		return errors.New("value parser: type or parser is nil")
+	}
+
+	if _, found := valueParsers[typ]; found {
+		return fmt.Errorf("value parser: type '%s' is already added", typ)
+	}
+
+	valueParsers[typ] = parser
+	return nil
+}
+
+//‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾
+// BindError methods
+//___________________________________
+
+// Error method is implementation of error interface.
+func (e *BindError) Error() string {
+	switch {
+	case ess.IsStrEmpty(e.Field):
+		return fmt.Sprintf("bind: %s", e.Message)
+	case ess.IsStrEmpty(e.Value):
+		return fmt.Sprintf("bind: field '%s': %s", e.Field, e.Message)
+	}
+	return fmt.Sprintf("bind: field '%s' value '%s': %s", e.Field, e.Value, e.Message)
+}
+
+// Error method is implementation of error interface.
+func (e *valueParserError) Error() string {
+	return fmt.Sprintf("value parser: type '%s' returned for '%s'", e.returned, e.expected)
+}
+
+// Error method is implementation of error interface.
+func (e *bindTargetError) Error() string {
+	return fmt.Sprintf("bind: form binding supports only struct type, not '%s'", e.typ)
+}
+
+//‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾
+// Unexported methods
+//___________________________________
+
+// bindParameter method returns the value for the given action parameter from
+// the request. Value is looked up in the order of Path, Form and Query params.
+// Multipart file(s) and Struct (JSON/XML Payload) are bound by type.
+func bindParameter(ctx *Context, param *ParameterInfo) (reflect.Value, error) {
+	params := ctx.Req.Params
+	if parser, found := valueParsers[param.Type]; found {
+		return parseCustomValue(parser, param.Name, param.Type, params)
+	}
+
+	switch param.Type {
+	case fileHeaderType:
+		if fhs := params.File[param.Name]; len(fhs) > 0 {
+			return reflect.ValueOf(fhs[0]), nil
+		}
+		return reflect.Zero(param.Type), nil
+	case fileHeadersType:
+		return reflect.ValueOf(params.File[param.Name]), nil
+	}
+
+	if isStructType(param.Type) {
+		if len(ctx.Req.Payload) > 0 {
+			return bindPayload(ctx, param.Type)
+		}
+
+		// struct fields are bound from request params without prefix
+		return bindValue("", param.Type, params)
+	}
+
+	return bindValue(param.Name, param.Type, params)
+}
+
+// bindPayload method unmarshals the request payload into new instance of
+// given type based on request content type.
+func bindPayload(ctx *Context, typ reflect.Type) (reflect.Value, error) {
+	isPtr := typ.Kind() == reflect.Ptr
+	if isPtr {
+		typ = typ.Elem()
+	}
+
+	value := reflect.New(typ)
+	var err error
+	switch contentType := ctx.Req.ContentType.Mime; {
+	case isJSONContentType(contentType):
+		err = decodeJSON(ctx, value.Interface())
+	case isXMLContentType(contentType):
+		err = decodeXML(ctx, value.Interface())
+	default:
+		err = fmt.Errorf("unsupported payload content type: %s", ctx.Req.ContentType.Mime)
+	}
+
+	if err != nil {
+		return reflect.Value{}, err
+	}
+
+	if isPtr {
+		return value, nil
+	}
+	return value.Elem(), nil
+}
+
+// decodeJSON method decodes the request JSON payload into given value.
+// Unknown fields are rejected in strict mode, refer `isStrictBind`.
+func decodeJSON(ctx *Context, v interface{}) error {
+	decoder := json.NewDecoder(payloadReader(ctx))
+	if isStrictBind(ctx) {
+		decoder.DisallowUnknownFields()
+	}
+
+	if err := decoder.Decode(v); err != nil {
+		return jsonBindError(err)
+	}
+	return nil
+}
+
+// decodeXML method decodes the request XML payload into given value.
+func decodeXML(ctx *Context, v interface{}) error {
+	if err := xml.NewDecoder(payloadReader(ctx)).Decode(v); err != nil {
+		if err == io.EOF {
+			err = errEmptyPayload
+		}
+		return newBindError("", "", err)
+	}
+	return nil
+}
+
+// decodeForm method binds the request params into given struct pointer
+// value. Form params that are not mapped to struct fields are rejected in
+// strict mode, refer `isStrictBind`.
+func decodeForm(ctx *Context, v interface{}) error {
+	rv := reflect.ValueOf(v)
+	typ := rv.Type().Elem()
+	if !isStructType(typ) {
+		return &bindTargetError{typ: typ}
+	}
+
+	if isStrictBind(ctx) {
+		for key := range ctx.Req.Params.Form {
+			if !isStructKey(typ, key) {
+				return newBindError(key, "", errors.New("unknown field"))
+			}
+		}
+	}
+
+	value, err := bindValue("", typ, ctx.Req.Params)
+	if err != nil {
+		return err
+	}
+	rv.Elem().Set(value)
+	return nil
+}
+
+// bindValue method converts the request param value(s) for the given key
+// into given type. Registered value parser takes precedence over built-in types.
+func bindValue(key string, typ reflect.Type, params *ahttp.Params) (reflect.Value, error) {
+	if parser, found := valueParsers[typ]; found {
+		return parseCustomValue(parser, key, typ, params)
+	}
+
+	switch typ.Kind() {
+	case reflect.Ptr:
+		// nested struct pointer is left nil unless params has its fields,
+		// it stops the recursion of self-referential types
+		if isStructType(typ) {
+			if _, found := valueParsers[typ.Elem()]; !found &&
+				!ess.IsStrEmpty(key) && !hasParamPrefix(key+".", params) {
+				return reflect.Zero(typ), nil
+			}
+		} else if len(paramValues(key, params)) == 0 {
+			return reflect.Zero(typ), nil
+		}
+
+		value, err := bindValue(key, typ.Elem(), params)
+		if err != nil {
+			return value, err
+		}
+
+		ptr := reflect.New(typ.Elem())
+		ptr.Elem().Set(value)
+		return ptr, nil
+	case reflect.Slice:
+		values := paramValues(key, params)
+		slice := reflect.MakeSlice(typ, 0, len(values))
+		parser, found := valueParsers[typ.Elem()]
+		for _, v := range values {
+			var (
+				value reflect.Value
+				err   error
+			)
+			if found {
+				value, err = parseCustomValue(parser, key, typ.Elem(), singleValueParams(key, v))
+			} else {
+				value, err = parseValue(key, v, typ.Elem())
+			}
+			if err != nil {
+				return value, err
+			}
+			slice = reflect.Append(slice, value)
+		}
+		return slice, nil
+	case reflect.Struct:
+		if typ != timeType {
+			return bindStruct(key, typ, params)
+		}
+	}
+
+	var value string
+	if values := paramValues(key, params); len(values) > 0 {
+		value = values[0]
+	}
+	return parseValue(key, value, typ)
+}
+
+// bindStruct method binds the request params into struct exported fields.
+// Field key is taken from the tag `bind` otherwise field name, nested struct
+// fields are prefixed with parent key. For e.g.: `address.city`.
+func bindStruct(prefix string, typ reflect.Type, params *ahttp.Params) (reflect.Value, error) {
+	value := reflect.New(typ).Elem()
+	for idx := 0; idx < typ.NumField(); idx++ {
+		field := typ.Field(idx)
+		if !ess.IsStrEmpty(field.PkgPath) { // unexported field
+			continue
+		}
+
+		key := field.Tag.Get(keyBindTag)
+		if key == "-" {
+			continue
+		}
+
+		if ess.IsStrEmpty(key) {
+			key = field.Name
+		}
+
+		if !ess.IsStrEmpty(prefix) {
+			key = prefix + "." + key
+		}
+
+		fv, err := bindValue(key, field.Type, params)
+		if err != nil {
+			return fv, err
+		}
+		value.Field(idx).Set(fv)
+	}
+
+	return value, nil
+}
+
+// isStructKey method returns true if given key is mapped to struct exported
+// field as per `bindStruct`, nested struct keys are matched field by field.
+func isStructKey(typ reflect.Type, key string) bool {
+	if typ.Kind() == reflect.Ptr {
+		typ = typ.Elem()
+	}
+
+	for idx := 0; idx < typ.NumField(); idx++ {
+		field := typ.Field(idx)
+		fieldKey := field.Tag.Get(keyBindTag)
+		if !ess.IsStrEmpty(field.PkgPath) || fieldKey == "-" {
+			continue
+		}
+
+		if ess.IsStrEmpty(fieldKey) {
+			fieldKey = field.Name
+		}
+
+		if _, found := valueParsers[field.Type]; !found && isStructType(field.Type) {
+			if strings.HasPrefix(key, fieldKey+".") && isStructKey(field.Type, key[len(fieldKey)+1:]) {
+				return true
+			}
+			continue
+		}
+
+		if key == fieldKey {
+			return true
+		}
+	}
+	return false
+}
+
+// parseValue method converts the given string value into given type.
+// Empty value returns the zero value of the type.
+func parseValue(key, str string, typ reflect.Type) (reflect.Value, error) {
+	value := reflect.New(typ).Elem()
+	if ess.IsStrEmpty(str) {
+		return value, nil
+	}
+
+	var err error
+	if typ == timeType {
+		var t time.Time
+		if t, err = parseTime(str); err == nil {
+			value.Set(reflect.ValueOf(t))
+			return value, nil
+		}
+		return value, newBindError(key, str, err)
+	}
+
+	switch typ.Kind() {
+	case reflect.String:
+		value.SetString(str)
+	case reflect.Bool:
+		var b bool
+		if b, err = strconv.ParseBool(str); err == nil {
+			value.SetBool(b)
+		}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		var i int64
+		if i, err = strconv.ParseInt(str, 10, typ.Bits()); err == nil {
+			value.SetInt(i)
+		}
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		var u uint64
+		if u, err = strconv.ParseUint(str, 10, typ.Bits()); err == nil {
+			value.SetUint(u)
+		}
+	case reflect.Float32, reflect.Float64:
+		var f float64
+		if f, err = strconv.ParseFloat(str, typ.Bits()); err == nil {
+			value.SetFloat(f)
+		}
+	default:
+		err = fmt.Errorf("unsupported type '%s'", typ)
+	}
+
+	if err != nil {
+		return value, newBindError(key, str, err)
+	}
+	return value, nil
+}
+
+// parseCustomValue method calls the registered value parser and ensures
+// returned value is assignable to given type.
+func parseCustomValue(parser ValueParser, key string, typ reflect.Type, params *ahttp.Params) (reflect.Value, error) {
+	value, err := parser(key, typ, params)
+	if err != nil {
+		return value, err
+	}
+
+	if !value.IsValid() {
+		return reflect.Zero(typ), nil
+	}
+
+	if value.Type() != typ {
+		if !value.Type().ConvertibleTo(typ) {
+			return value, &valueParserError{returned: value.Type(), expected: typ}
+		}
+		value = value.Convert(typ)
+	}
+
+	return value, nil
+}
+
+// singleValueParams method returns the params with given value for the key
+// in Path, Form and Query params, it's passed to value parser of slice element.
+func singleValueParams(key, value string) *ahttp.Params {
+	return &ahttp.Params{
+		Path:  map[string]string{key: value},
+		Query: url.Values{key: []string{value}},
+		Form:  url.Values{key: []string{value}},
+	}
+}
+
+// parseTime method parses the given value with `format.datetime`,
+// `format.date` and RFC3339 format in that order.
+func parseTime(str string) (time.Time, error) {
+	var (
+		t   time.Time
+		err error
+	)
+
+	for _, layout := range []string{AppDateTimeFormat(), AppDateFormat(), time.RFC3339} {
+		if t, err = time.Parse(layout, str); err == nil {
+			return t, nil
+		}
+	}
+
+	return t, err
+}
+
+// paramValues method returns the values for given key from Path, Form and
+// Query params in that order.
+func paramValues(key string, params *ahttp.Params) []string {
+	if value, found := params.Path[key]; found {
+		return []string{value}
+	}
+
+	if values, found := params.Form[key]; found {
+		return values
+	}
+
+	return params.Query[key]
+}
+
+// hasParamPrefix method returns true if any of Path, Form, Query and File
+// params key has given prefix.
+func hasParamPrefix(prefix string, params *ahttp.Params) bool {
+	for key := range params.Path {
+		if strings.HasPrefix(key, prefix) {
+			return true
+		}
+	}
+
+	for _, values := range []map[string][]string{params.Form, params.Query} {
+		for key := range values {
+			if strings.HasPrefix(key, prefix) {
+				return true
+			}
+		}
+	}
+
+	for key := range params.File {
+		if strings.HasPrefix(key, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// isStructType method returns true if given type or pointer type is struct
+// and not `time.Time` otherwise false.
+func isStructType(typ reflect.Type) bool {
+	if typ.Kind() == reflect.Ptr {
+		typ = typ.Elem()
+	}
+	return typ.Kind() == reflect.Struct && typ != timeType
+}
+
+// payloadReader method returns the reader of request payload, request body
+// is read directly for streaming body route.
+func payloadReader(ctx *Context) io.Reader {
+	if ctx.Req.Payload == nil && ctx.Req.Raw.Body != nil {
+		return ctx.Req.Raw.Body
+	}
+	return bytes.NewReader(ctx.Req.Payload)
+}
+
+// isStrictBind method returns true if unknown fields to be rejected while
+// binding the request, route `bind.strict` from routes.conf otherwise
+// `request.bind.strict` from aah.conf.
+func isStrictBind(ctx *Context) bool {
+	return routeBoolDefault(ctx, "bind.strict",
+		AppConfig().BoolDefault("request.bind.strict", false))
+}
+
+// newBindError method returns the bind error for the given field, value
+// and cause.
+func newBindError(field, value string, err error) *BindError {
+	return &BindError{Field: field, Value: value, Message: err.Error(), Err: err}
+}
+
+// jsonBindError method returns the bind error for the given JSON decode
+// error with offending field if available.
+func jsonBindError(err error) *BindError {
+	switch e := err.(type) {
+	case *json.UnmarshalTypeError:
+		return newBindError(e.Field, e.Value, err)
+	case *json.SyntaxError:
+		return newBindError("", "", err)
+	}
+
+	if err == io.EOF {
+		return newBindError("", "", errEmptyPayload)
+	}
+
+	if field, found := jsonUnknownField(err); found {
+		return newBindError(field, "", errors.New("unknown field"))
+	}
+	return newBindError("", "", err)
+}
+
+// jsonUnknownField method returns the field name of strict mode unknown field
+// error, e.g. `json: unknown field "nickname"`. JSON decoder doesn't have the
+// typed error for it.
+func jsonUnknownField(err error) (string, bool) {
+	const prefix = "json: unknown field "
+	msg := err.Error()
+	if !strings.HasPrefix(msg, prefix) {
+		return "", false
+	}
+
+	field, err := strconv.Unquote(strings.TrimPrefix(msg, prefix))
+	return field, err == nil
+}
+
+// replyBindError method replies HTTP Bad Request (Status 400) for the
+// binding error via error handler, `HTTPError.Data` holds the bind error.
+// Value parser misconfiguration and unsupported bind type reply HTTP Internal
+// Server Error (Status 500).
+func replyBindError(ctx *Context, err error) {
+	switch err.(type) {
+	case *valueParserError, *bindTargetError:
+		ctx.Log().Errorf("Bind misconfiguration on %s: %s", ctx.Req.Path, err)
+		handleError(ctx, &HTTPError{Code: http.StatusInternalServerError, Err: err})
+		return
+	}
+
+	ctx.Log().Errorf("Bad Request on %s: %s", ctx.Req.Path, err)
+	httpErr := &HTTPError{Code: http.StatusBadRequest, Err: err}
+	if be, ok := err.(*BindError); ok {
+		httpErr.Data = be
+	}
+	handleError(ctx, httpErr)
+}
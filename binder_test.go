@@ -0,0 +1,381 @@
+// Copyright (c) Jeevanandam M. (https://github.com/jeevatkm)
+// go-aah/aah source code and usage is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package aah
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http/httptest"
+	"net/url"
+	"reflect"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"aahframework.org/ahttp.v0"
+	"aahframework.org/config.v0"
+	"aahframework.org/router.v0"
+	"aahframework.org/test.v0/assert"
+)
+
+type (
+	bindAddress struct {
+		City    string
+		ZipCode int `bind:"zip"`
+	}
+
+	bindUser struct {
+		Name     string `bind:"name" json:"name"`
+		Age      int    `bind:"age" json:"age"`
+		Emails   []string
+		Birthday *time.Time
+		Address  bindAddress
+		Ignore   string `bind:"-"`
+		internal string
+	}
+
+	bindCategory struct {
+		Name   string
+		Parent *bindCategory
+	}
+
+	Binder struct {
+		*Context
+	}
+)
+
+func (b *Binder) Show(id int, tags []string, active *bool) {
+	b.Reply().Text("id: %d, tags: %s, active: %v", id, strings.Join(tags, ","), *active)
+}
+
+func TestBinderParseValue(t *testing.T) {
+	appConfig, _ = config.ParseString("")
+
+	v, err := parseValue("count", "100", reflect.TypeOf(int64(0)))
+	assert.Nil(t, err)
+	assert.Equal(t, int64(100), v.Int())
+
+	v, err = parseValue("ratio", "1.5", reflect.TypeOf(float32(0)))
+	assert.Nil(t, err)
+	assert.Equal(t, float64(1.5), v.Float())
+
+	v, err = parseValue("active", "true", reflect.TypeOf(true))
+	assert.Nil(t, err)
+	assert.True(t, v.Bool())
+
+	v, err = parseValue("count", "", reflect.TypeOf(uint(0)))
+	assert.Nil(t, err)
+	assert.Equal(t, uint64(0), v.Uint())
+
+	v, err = parseValue("date", "2017-05-20", timeType)
+	assert.Nil(t, err)
+	assert.Equal(t, 2017, v.Interface().(time.Time).Year())
+
+	v, err = parseValue("datetime", "2017-05-20 10:30:45", timeType)
+	assert.Nil(t, err)
+	assert.Equal(t, 30, v.Interface().(time.Time).Minute())
+
+	_, err = parseValue("count", "abc", reflect.TypeOf(0))
+	assert.NotNil(t, err)
+	assert.True(t, strings.HasPrefix(err.Error(), "bind: field 'count' value 'abc'"))
+
+	_, err = parseValue("ch", "value", reflect.TypeOf(make(chan int)))
+	assert.NotNil(t, err)
+}
+
+func TestBinderBindValue(t *testing.T) {
+	appConfig, _ = config.ParseString("")
+
+	params := &ahttp.Params{
+		Path: map[string]string{"name": "John"},
+		Form: url.Values{
+			"age":             []string{"28"},
+			"Emails":          []string{"john@example.com", "john@sample.com"},
+			"Birthday":        []string{"1989-04-15"},
+			"Address.City":    []string{"Chennai"},
+			"Address.zip":     []string{"600001"},
+			"Ignore":          []string{"ignore value"},
+			"internal":        []string{"internal value"},
+			"invalid_integer": []string{"28a"},
+		},
+		Query: url.Values{"name": []string{"Jane"}, "ids": []string{"1", "2", "3"}},
+	}
+
+	v, err := bindValue("", reflect.TypeOf((*bindUser)(nil)), params)
+	assert.Nil(t, err)
+	user := v.Interface().(*bindUser)
+	assert.Equal(t, "John", user.Name)
+	assert.Equal(t, 28, user.Age)
+	assert.Equal(t, []string{"john@example.com", "john@sample.com"}, user.Emails)
+	assert.NotNil(t, user.Birthday)
+	assert.Equal(t, time.April, user.Birthday.Month())
+	assert.Equal(t, "Chennai", user.Address.City)
+	assert.Equal(t, 600001, user.Address.ZipCode)
+	assert.Equal(t, "", user.Ignore)
+	assert.Equal(t, "", user.internal)
+
+	v, err = bindValue("ids", reflect.TypeOf([]int{}), params)
+	assert.Nil(t, err)
+	assert.Equal(t, []int{1, 2, 3}, v.Interface())
+
+	v, err = bindValue("notexists", reflect.TypeOf((*int)(nil)), params)
+	assert.Nil(t, err)
+	assert.True(t, v.IsNil())
+
+	_, err = bindValue("invalid_integer", reflect.TypeOf(0), params)
+	assert.NotNil(t, err)
+}
+
+func TestBinderBindSelfReferential(t *testing.T) {
+	appConfig, _ = config.ParseString("")
+
+	params := &ahttp.Params{Form: url.Values{
+		"Name":             []string{"Phones"},
+		"Parent.Name":      []string{"Electronics"},
+		"Parent.Parent.ID": []string{"1"},
+	}}
+	v, err := bindValue("", reflect.TypeOf((*bindCategory)(nil)), params)
+	assert.Nil(t, err)
+	category := v.Interface().(*bindCategory)
+	assert.Equal(t, "Phones", category.Name)
+	assert.Equal(t, "Electronics", category.Parent.Name)
+	assert.NotNil(t, category.Parent.Parent)
+	assert.Nil(t, category.Parent.Parent.Parent)
+
+	v, err = bindValue("", reflect.TypeOf(bindCategory{}), &ahttp.Params{})
+	assert.Nil(t, err)
+	assert.Nil(t, v.Interface().(bindCategory).Parent)
+
+	typ := reflect.TypeOf(bindCategory{})
+	assert.True(t, isStructKey(typ, "Parent.Parent.Name"))
+	assert.False(t, isStructKey(typ, "Parent.Parent.ID"))
+	assert.False(t, isStructKey(typ, "Parent"))
+}
+
+func TestBinderBindParameter(t *testing.T) {
+	appConfig, _ = config.ParseString("")
+
+	req := httptest.NewRequest("POST", "http://localhost:8080/users", nil)
+	req.Header.Set(ahttp.HeaderContentType, ahttp.ContentTypeJSON.Raw())
+	ctx := &Context{Req: ahttp.ParseRequest(req, &ahttp.Request{})}
+	ctx.Req.Payload = []byte(`{"name":"John","age":28}`)
+
+	v, err := bindParameter(ctx, &ParameterInfo{Name: "user", Type: reflect.TypeOf(bindUser{})})
+	assert.Nil(t, err)
+	assert.Equal(t, "John", v.Interface().(bindUser).Name)
+	assert.Equal(t, 28, v.Interface().(bindUser).Age)
+
+	ctx.Req.Payload = []byte(`{"name":"John","age":"28"}`)
+	_, err = bindParameter(ctx, &ParameterInfo{Name: "user", Type: reflect.TypeOf((*bindUser)(nil))})
+	assert.NotNil(t, err)
+
+	v, err = bindParameter(ctx, &ParameterInfo{Name: "file", Type: fileHeaderType})
+	assert.Nil(t, err)
+	assert.True(t, v.IsNil())
+}
+
+func TestBinderActionMiddleware(t *testing.T) {
+	appConfig, _ = config.ParseString("")
+
+	cRegistry = controllerRegistry{}
+	AddController((*Binder)(nil), []*MethodInfo{
+		{
+			Name: "Show",
+			Parameters: []*ParameterInfo{
+				{Name: "id", Type: reflect.TypeOf(0)},
+				{Name: "tags", Type: reflect.TypeOf([]string{})},
+				{Name: "active", Type: reflect.TypeOf((*bool)(nil))},
+			},
+		},
+	})
+
+	req := httptest.NewRequest("GET", "http://localhost:8080/binder/1001?tags=aah&tags=go&active=true", nil)
+	ctx := &Context{
+		Req:   ahttp.ParseRequest(req, &ahttp.Request{}),
+		reply: NewReply(),
+	}
+	ctx.Req.Params.Path = map[string]string{"id": "1001"}
+	ci := cRegistry.Lookup(&router.Route{Controller: "Binder"})
+	ctx.action = ci.FindMethod("Show")
+	ctx.target = &Binder{Context: ctx}
+
+	actionMiddleware(ctx, &Middleware{})
+	assert.Equal(t, 200, ctx.Reply().Code)
+	assert.Equal(t, "id: %d, tags: %s, active: %v", ctx.Reply().Rdr.(*Text).Format)
+	assert.Equal(t, []interface{}{1001, "aah,go", true}, ctx.Reply().Rdr.(*Text).Values)
+
+	// invalid value
+	ctx.reply = NewReply()
+	ctx.Req.Params.Path["id"] = "abc"
+	actionMiddleware(ctx, &Middleware{})
+	assert.Equal(t, 400, ctx.Reply().Code)
+}
+
+type bindGeoPoint struct {
+	Lat, Lng float64
+}
+
+func TestBinderValueParser(t *testing.T) {
+	defer func() {
+		valueParsers = make(map[reflect.Type]ValueParser)
+	}()
+
+	geoPointType := reflect.TypeOf(bindGeoPoint{})
+	err := AddValueParser(geoPointType, func(key string, typ reflect.Type, params *ahttp.Params) (reflect.Value, error) {
+		parts := strings.Split(params.QueryValue(key), ",")
+		if len(parts) != 2 {
+			return reflect.Value{}, errors.New("invalid geo point")
+		}
+
+		lat, _ := strconv.ParseFloat(parts[0], 64)
+		lng, _ := strconv.ParseFloat(parts[1], 64)
+		return reflect.ValueOf(bindGeoPoint{Lat: lat, Lng: lng}), nil
+	})
+	assert.Nil(t, err)
+
+	err = AddValueParser(geoPointType, nil)
+	assert.Equal(t, "value parser: type or parser is nil", err.Error())
+
+	err = AddValueParser(geoPointType, func(key string, typ reflect.Type, params *ahttp.Params) (reflect.Value, error) {
+		return reflect.Value{}, nil
+	})
+	assert.Equal(t, "value parser: type 'aah.bindGeoPoint' is already added", err.Error())
+
+	params := &ahttp.Params{Query: url.Values{"location": []string{"13.08,80.27"}}}
+	v, err := bindValue("location", geoPointType, params)
+	assert.Nil(t, err)
+	assert.Equal(t, bindGeoPoint{Lat: 13.08, Lng: 80.27}, v.Interface())
+
+	v, err = bindValue("location", reflect.PtrTo(geoPointType), params)
+	assert.Nil(t, err)
+	assert.Equal(t, &bindGeoPoint{Lat: 13.08, Lng: 80.27}, v.Interface())
+
+	req := httptest.NewRequest("GET", "http://localhost:8080/places?location=13.08", nil)
+	ctx := &Context{Req: ahttp.ParseRequest(req, &ahttp.Request{})}
+	_, err = bindParameter(ctx, &ParameterInfo{Name: "location", Type: geoPointType})
+	assert.Equal(t, "invalid geo point", err.Error())
+
+	// slice elements are parsed with element type parser
+	params = &ahttp.Params{Query: url.Values{"route": []string{"13.08,80.27", "12.97,77.59"}}}
+	v, err = bindValue("route", reflect.SliceOf(geoPointType), params)
+	assert.Nil(t, err)
+	assert.Equal(t, []bindGeoPoint{{Lat: 13.08, Lng: 80.27}, {Lat: 12.97, Lng: 77.59}}, v.Interface())
+
+	params.Query["route"] = []string{"13.08,80.27", "12.97"}
+	_, err = bindValue("route", reflect.SliceOf(geoPointType), params)
+	assert.Equal(t, "invalid geo point", err.Error())
+
+	// misconfigured parser is server error
+	typ := reflect.TypeOf(time.Duration(0))
+	assert.Nil(t, AddValueParser(typ, func(key string, typ reflect.Type, params *ahttp.Params) (reflect.Value, error) {
+		return reflect.ValueOf("5s"), nil
+	}))
+	ctx.reply = NewReply()
+	_, err = bindParameter(ctx, &ParameterInfo{Name: "timeout", Type: typ})
+	assert.Equal(t, "value parser: type 'string' returned for 'time.Duration'", err.Error())
+	replyBindError(ctx, err)
+	assert.Equal(t, 500, ctx.Reply().Code)
+}
+
+func TestBinderContextBind(t *testing.T) {
+	appConfig, _ = config.ParseString("")
+
+	newCtx := func(contentType, payload string) *Context {
+		req := httptest.NewRequest("POST", "http://localhost:8080/users?age=28", strings.NewReader(payload))
+		req.Header.Set(ahttp.HeaderContentType, contentType)
+		ctx := &Context{Req: ahttp.ParseRequest(req, &ahttp.Request{}), reply: NewReply()}
+		if contentType == ahttp.ContentTypeForm.Raw() {
+			_ = req.ParseForm()
+			ctx.Req.Params.Form = req.PostForm
+		} else {
+			ctx.Req.Payload = []byte(payload)
+		}
+		return ctx
+	}
+
+	// JSON
+	var user bindUser
+	ctx := newCtx(ahttp.ContentTypeJSON.Raw(), `{"name":"John","age":28,"nickname":"jo"}`)
+	assert.Nil(t, ctx.Bind(&user))
+	assert.Equal(t, "John", user.Name)
+	assert.Equal(t, 28, user.Age)
+
+	ctx = newCtx(ahttp.ContentTypeJSON.Raw(), `{"name":"John","age":"28"}`)
+	err := ctx.Bind(&user)
+	be := err.(*BindError)
+	assert.Equal(t, "age", be.Field)
+	assert.Equal(t, "string", be.Value)
+	assert.Equal(t, 400, ctx.Reply().Code)
+
+	ctx = newCtx(ahttp.ContentTypeJSON.Raw(), "")
+	assert.Equal(t, "bind: request payload is empty", ctx.BindJSON(&user).Error())
+
+	// XML
+	ctx = newCtx(ahttp.ContentTypeXML.Raw(), `<bindUser><Name>Jane</Name><Age>30</Age></bindUser>`)
+	user = bindUser{}
+	assert.Nil(t, ctx.Bind(&user))
+	assert.Equal(t, "Jane", user.Name)
+	assert.Equal(t, 30, user.Age)
+
+	// Form
+	ctx = newCtx(ahttp.ContentTypeForm.Raw(), "name=Jeeva&Emails=a@aah.io&Address.City=Chennai&Address.zip=600001")
+	user = bindUser{}
+	assert.Nil(t, ctx.Bind(&user))
+	assert.Equal(t, "Jeeva", user.Name)
+	assert.Equal(t, 28, user.Age)
+	assert.Equal(t, []string{"a@aah.io"}, user.Emails)
+	assert.Equal(t, bindAddress{City: "Chennai", ZipCode: 600001}, user.Address)
+
+	ctx = newCtx(ahttp.ContentTypeForm.Raw(), "name=Jeeva&Address.zip=abc")
+	err = ctx.BindForm(&user)
+	assert.Equal(t, "Address.zip", err.(*BindError).Field)
+	assert.Equal(t, "abc", err.(*BindError).Value)
+	assert.Equal(t, "400 Bad Request\nbind: field 'Address.zip' value 'abc': strconv.ParseInt: parsing \"abc\": invalid syntax",
+		ctx.Reply().Rdr.(*Text).Format)
+
+	// invalid values
+	ctx = newCtx(ahttp.ContentTypeForm.Raw(), "")
+	assert.Equal(t, "bind: value must be a non-nil pointer", ctx.Bind(user).Error())
+	assert.Equal(t, 500, ctx.Reply().Code)
+
+	var count int
+	ctx = newCtx(ahttp.ContentTypeForm.Raw(), "")
+	assert.Equal(t, "bind: form binding supports only struct type, not 'int'", ctx.Bind(&count).Error())
+	assert.Equal(t, 500, ctx.Reply().Code)
+
+	// strict mode
+	appConfig.SetBool("request.bind.strict", true)
+	defer appConfig.SetBool("request.bind.strict", false)
+
+	ctx = newCtx(ahttp.ContentTypeJSON.Raw(), `{"name":"John","nickname":"jo"}`)
+	err = ctx.Bind(&user)
+	assert.Equal(t, "bind: field 'nickname': unknown field", err.Error())
+
+	ctx = newCtx(ahttp.ContentTypeForm.Raw(), "name=Jeeva&Address.City=Chennai&Ignore=yes")
+	err = ctx.Bind(&user)
+	assert.Equal(t, "Ignore", err.(*BindError).Field)
+
+	ctx = newCtx(ahttp.ContentTypeForm.Raw(), "name=Jeeva&Address.City=Chennai&Birthday=2017-05-20")
+	assert.Nil(t, ctx.Bind(&user))
+}
+
+func TestBinderJSONUnknownField(t *testing.T) {
+	decoder := json.NewDecoder(strings.NewReader(`{"name":"John","nickname":"jo"}`))
+	decoder.DisallowUnknownFields()
+	var user struct{ Name string }
+	err := decoder.Decode(&user)
+
+	field, found := jsonUnknownField(err)
+	assert.True(t, found)
+	assert.Equal(t, "nickname", field)
+
+	field, found = jsonUnknownField(errors.New("json: unknown field nickname"))
+	assert.False(t, found)
+	assert.Equal(t, "", field)
+
+	_, found = jsonUnknownField(errEmptyPayload)
+	assert.False(t, found)
+}
@@ -0,0 +1,610 @@
+// Copyright (c) Jeevanandam M. (https://github.com/jeevatkm)
+// go-aah/aah source code and usage is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package aah
+
+import (
+	"bytes"
+	"container/list"
+	"crypto/sha1"
+	"encoding/gob"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"aahframework.org/ahttp.v0"
+	"aahframework.org/config.v0"
+	"aahframework.org/essentials.v0"
+	"aahframework.org/log.v0"
+)
+
+const (
+	cacheStoreMemory = "memory"
+	cacheStoreFile   = "file"
+	cacheFileExt     = ".cache"
+	headerAge        = "Age"
+	headerSetCookie  = "Set-Cookie"
+)
+
+var (
+	appCacheStore        CacheStore
+	appCacheTTL          time.Duration
+	appCacheCredentialed bool
+	appCacheSessionName  = "aah_session"
+	appRouteCacheTTLs    = make(map[string]time.Duration)
+)
+
+type (
+	// CacheStore interface is used to store the cached replies of response
+	// cache. Framework provides in-memory LRU and file system store, refer
+	// `NewMemoryCacheStore` and `NewFileCacheStore`. Implement this interface
+	// to use distributed cache such as Redis, Memcached, etc.
+	CacheStore interface {
+		// Get method returns the cached reply for the given key otherwise nil.
+		Get(key string) *CachedReply
+
+		// Put method stores the given cached reply for the given key.
+		Put(key string, reply *CachedReply) error
+
+		// Delete method removes the cached reply of the given key.
+		Delete(key string) error
+
+		// Purge method removes the cached replies which key has given prefix,
+		// empty prefix removes all the cached replies.
+		Purge(prefix string) error
+	}
+
+	// CachedReply holds the rendered reply status, headers and body. Reply
+	// with `Vary` header is stored under the key which includes the request
+	// values of vary headers, cached reply of the request key just holds
+	// the `Vary` header names.
+	CachedReply struct {
+		Key         string
+		Code        int
+		ContentType string
+		Header      http.Header
+		Body        []byte
+		Vary        []string
+		CreatedAt   time.Time
+		ExpiresAt   time.Time
+	}
+
+	// MemoryCacheStore is in-memory LRU cache store, least recently used cached
+	// reply is evicted when it reaches max entries.
+	MemoryCacheStore struct {
+		mu         sync.Mutex
+		maxEntries int
+		ll         *list.List
+		entries    map[string]*list.Element
+	}
+
+	// FileCacheStore is file system cache store, each cached reply is stored
+	// as a file in the directory.
+	FileCacheStore struct {
+		mu  sync.RWMutex
+		dir string
+	}
+)
+
+//‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾
+// Global methods
+//___________________________________
+
+// SetCacheStore method sets the given store for response cache, it overrides
+// the store configured via aah.conf `cache.response.store`.
+func SetCacheStore(store CacheStore) {
+	appCacheStore = store
+}
+
+// AppCacheStore method returns the response cache store.
+func AppCacheStore() CacheStore {
+	return appCacheStore
+}
+
+// PurgeCache method removes the cached replies of the given request path
+// for all the hosts, methods, query params and locales. Empty path removes
+// all the cached replies.
+//		For Example:
+//
+//		// after the product is updated
+//		aah.PurgeCache("/products/" + productID)
+func PurgeCache(path string) error {
+	if appCacheStore == nil {
+		return nil
+	}
+
+	if ess.IsStrEmpty(path) {
+		return appCacheStore.Purge("")
+	}
+	return appCacheStore.Purge(path + "\x00")
+}
+
+// CacheMiddleware method returns the middleware which caches the replies of
+// `GET` and `HEAD` requests with given TTL. It's an alternative to route
+// level `cache` configuration in routes.conf. Add it after the
+// authentication middleware, cached reply is written without calling
+// the further middlewares.
+//		For Example:
+//
+//		aah.Middlewares(AuthMiddleware, aah.CacheMiddleware(5 * time.Minute))
+func CacheMiddleware(ttl time.Duration) MiddlewareFunc {
+	return func(ctx *Context, m *Middleware) {
+		if ctx.cacheTTL == 0 && checkCache(ctx, ttl) {
+			ctx.Abort()
+			return
+		}
+		m.Next(ctx)
+	}
+}
+
+// NewMemoryCacheStore method returns the in-memory LRU cache store with
+// given max entries.
+func NewMemoryCacheStore(maxEntries int) *MemoryCacheStore {
+	return &MemoryCacheStore{
+		maxEntries: maxEntries,
+		ll:         list.New(),
+		entries:    make(map[string]*list.Element),
+	}
+}
+
+// NewFileCacheStore method returns the file system cache store for the given
+// directory, directory is created if not exists. Directory and cached reply
+// files are accessible only by the owner.
+func NewFileCacheStore(dir string) (*FileCacheStore, error) {
+	if err := ess.MkDirAll(dir, 0700); err != nil {
+		return nil, err
+	}
+	return &FileCacheStore{dir: dir}, nil
+}
+
+//‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾
+// CachedReply methods
+//___________________________________
+
+// IsExpired method returns true if cached reply is expired.
+func (cr *CachedReply) IsExpired() bool {
+	return time.Now().After(cr.ExpiresAt)
+}
+
+//‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾
+// MemoryCacheStore methods
+//___________________________________
+
+// Get method returns the cached reply for the given key otherwise nil.
+func (s *MemoryCacheStore) Get(key string) *CachedReply {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	elem, found := s.entries[key]
+	if !found {
+		return nil
+	}
+
+	cr := elem.Value.(*CachedReply)
+	if cr.IsExpired() {
+		s.removeElement(elem)
+		return nil
+	}
+
+	s.ll.MoveToFront(elem)
+	return cr
+}
+
+// Put method stores the given cached reply for the given key, least recently
+// used cached reply is evicted if store is full.
+func (s *MemoryCacheStore) Put(key string, reply *CachedReply) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	reply.Key = key
+	if elem, found := s.entries[key]; found {
+		elem.Value = reply
+		s.ll.MoveToFront(elem)
+		return nil
+	}
+
+	s.entries[key] = s.ll.PushFront(reply)
+	if s.maxEntries > 0 && s.ll.Len() > s.maxEntries {
+		s.removeElement(s.ll.Back())
+	}
+	return nil
+}
+
+// Delete method removes the cached reply of the given key.
+func (s *MemoryCacheStore) Delete(key string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if elem, found := s.entries[key]; found {
+		s.removeElement(elem)
+	}
+	return nil
+}
+
+// Purge method removes the cached replies which key has given prefix.
+func (s *MemoryCacheStore) Purge(prefix string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for key, elem := range s.entries {
+		if strings.HasPrefix(key, prefix) {
+			s.removeElement(elem)
+		}
+	}
+	return nil
+}
+
+// Len method returns the count of cached replies in the store.
+func (s *MemoryCacheStore) Len() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.ll.Len()
+}
+
+func (s *MemoryCacheStore) removeElement(elem *list.Element) {
+	s.ll.Remove(elem)
+	delete(s.entries, elem.Value.(*CachedReply).Key)
+}
+
+//‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾
+// FileCacheStore methods
+//___________________________________
+
+// Get method returns the cached reply for the given key otherwise nil.
+func (s *FileCacheStore) Get(key string) *CachedReply {
+	s.mu.RLock()
+	cr, err := s.read(s.filename(key))
+	s.mu.RUnlock()
+	if err != nil {
+		if !os.IsNotExist(err) {
+			log.Errorf("cache: %s", err)
+		}
+		return nil
+	}
+
+	if cr.Key != key {
+		return nil
+	}
+
+	if cr.IsExpired() {
+		_ = s.Delete(key)
+		return nil
+	}
+	return cr
+}
+
+// Put method stores the given cached reply for the given key.
+func (s *FileCacheStore) Put(key string, reply *CachedReply) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	reply.Key = key
+	filename := s.filename(key)
+	tmpFile := filename + ".tmp"
+	file, err := os.OpenFile(tmpFile, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0600)
+	if err != nil {
+		return err
+	}
+
+	if err = gob.NewEncoder(file).Encode(reply); err != nil {
+		ess.CloseQuietly(file)
+		_ = os.Remove(tmpFile)
+		return err
+	}
+	ess.CloseQuietly(file)
+
+	return os.Rename(tmpFile, filename)
+}
+
+// Delete method removes the cached reply of the given key.
+func (s *FileCacheStore) Delete(key string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if err := os.Remove(s.filename(key)); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	return nil
+}
+
+// Purge method removes the cached replies which key has given prefix.
+func (s *FileCacheStore) Purge(prefix string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	files, err := filepath.Glob(filepath.Join(s.dir, "*"+cacheFileExt))
+	if err != nil {
+		return err
+	}
+
+	for _, file := range files {
+		if !ess.IsStrEmpty(prefix) {
+			cr, err := s.read(file)
+			if err == nil && !strings.HasPrefix(cr.Key, prefix) {
+				continue
+			}
+		}
+
+		if err := os.Remove(file); err != nil && !os.IsNotExist(err) {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *FileCacheStore) filename(key string) string {
+	sum := sha1.Sum([]byte(key))
+	return filepath.Join(s.dir, hex.EncodeToString(sum[:])+cacheFileExt)
+}
+
+func (s *FileCacheStore) read(filename string) (*CachedReply, error) {
+	file, err := os.Open(filename)
+	if err != nil {
+		return nil, err
+	}
+	defer ess.CloseQuietly(file)
+
+	cr := &CachedReply{}
+	if err = gob.NewDecoder(file).Decode(cr); err != nil {
+		return nil, fmt.Errorf("%s: %s", filename, err)
+	}
+	return cr, nil
+}
+
+//‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾
+// Unexported methods
+//___________________________________
+
+// initResponseCache method creates the response cache store as per aah.conf
+// `cache.response.store` unless it's set by application and reads the
+// route level cache configuration from routes.conf.
+func initResponseCache(appCfg *config.Config) error {
+	ttl, err := time.ParseDuration(appCfg.StringDefault("cache.response.ttl", "5m"))
+	if err != nil {
+		return fmt.Errorf("'cache.response.ttl' value is not a valid time unit: %s", err)
+	}
+	appCacheTTL = ttl
+	appCacheCredentialed = appCfg.BoolDefault("cache.response.credentialed", false)
+	appCacheSessionName = appCfg.StringDefault("security.session.name", "aah_session")
+
+	if appCacheStore == nil {
+		switch storeName := appCfg.StringDefault("cache.response.store", cacheStoreMemory); storeName {
+		case cacheStoreMemory:
+			appCacheStore = NewMemoryCacheStore(appCfg.IntDefault("cache.response.memory.max_entries", 1000))
+		case cacheStoreFile:
+			dir := appCfg.StringDefault("cache.response.file.dir", filepath.Join(os.TempDir(), AppName()+"-cache"))
+			if appCacheStore, err = NewFileCacheStore(dir); err != nil {
+				return fmt.Errorf("'cache.response.file.dir' %s", err)
+			}
+
+			// existing directory could be created by other user, e.g. in temp dir
+			if fi, err := os.Stat(dir); err != nil || fi.Mode().Perm()&0077 != 0 {
+				appCacheStore = nil
+				return fmt.Errorf("'cache.response.file.dir' %s must be accessible only by the owner", dir)
+			}
+		default:
+			return fmt.Errorf("'cache.response.store' value '%s' is not supported", storeName)
+		}
+	}
+
+	appRouteCacheTTLs = make(map[string]time.Duration)
+	for _, path := range appRouteConfigPaths {
+		if !appRoutesConfig.BoolDefault(path+".cache.enable", false) {
+			continue
+		}
+
+		key := path + ".cache.ttl"
+		routeTTL, err := time.ParseDuration(appRoutesConfig.StringDefault(key, appCacheTTL.String()))
+		if err != nil {
+			return fmt.Errorf("'%s' value is not a valid time unit: %s", key, err)
+		}
+		appRouteCacheTTLs[path] = routeTTL
+	}
+
+	return nil
+}
+
+// checkCache method enables the response cache for the request with given
+// TTL and returns true if cached reply is found. Request header
+// `Cache-Control: no-cache` bypasses the cached reply, fresh reply gets
+// cached. Requests with `Authorization` header or session cookie are not
+// cached unless aah.conf `cache.response.credentialed` is enabled.
+func checkCache(ctx *Context, ttl time.Duration) bool {
+	if appCacheStore == nil || ttl <= 0 ||
+		!(ctx.Req.Method == ahttp.MethodGet || ctx.Req.Method == ahttp.MethodHead) ||
+		(!appCacheCredentialed && isCredentialedRequest(ctx)) {
+		return false
+	}
+
+	ctx.cacheTTL = ttl
+	ctx.cacheKey = responseCacheKey(ctx)
+	if strings.Contains(ctx.Req.Header.Get(headerCacheControl), "no-cache") {
+		ctx.Log().Debugf("Response cache bypassed: %s", ctx.Req.Path)
+		return false
+	}
+
+	cr := appCacheStore.Get(ctx.cacheKey)
+	if cr != nil && len(cr.Vary) > 0 {
+		cr = appCacheStore.Get(varyCacheKey(ctx, ctx.cacheKey, cr.Vary))
+	}
+	if cr == nil || cr.IsExpired() {
+		return false
+	}
+
+	ctx.Log().Debugf("Response cache hit: %s", ctx.Req.Path)
+	ctx.cachedReply = cr
+	return true
+}
+
+// isCredentialedRequest method returns true if request carries the
+// `Authorization` header or session cookie, its reply is user specific.
+func isCredentialedRequest(ctx *Context) bool {
+	if !ess.IsStrEmpty(ctx.Req.Header.Get(ahttp.HeaderAuthorization)) {
+		return true
+	}
+
+	_, err := ctx.Req.Raw.Cookie(appCacheSessionName)
+	return err == nil
+}
+
+// routeCacheTTL method returns the cache TTL of the request route from
+// routes.conf, zero if it's not enabled.
+func routeCacheTTL(ctx *Context) time.Duration {
+	return appRouteCacheTTLs[routeConfigPath(ctx)]
+}
+
+// storeCache method stores the rendered reply into response cache store.
+// Reply is not cached if it's not `200 OK` or it sets cookies or session or
+// `Vary: *`. Request ID header is not stored, cached reply carries the ID of
+// the request it's served to.
+func storeCache(ctx *Context) {
+	reply := ctx.Reply()
+	if ctx.cacheTTL <= 0 || reply.noCache || reply.Code != http.StatusOK ||
+		len(reply.cookies) > 0 || ctx.session != nil ||
+		!ess.IsStrEmpty(reply.Hdr.Get(headerSetCookie)) {
+		return
+	}
+
+	vary := varyHeaderNames(reply.Hdr)
+	for _, name := range vary {
+		if name == "*" {
+			return
+		}
+	}
+
+	now := time.Now()
+	cr := &CachedReply{
+		Code:        reply.Code,
+		ContentType: reply.ContType,
+		Header:      make(http.Header, len(reply.Hdr)),
+		Body:        append([]byte(nil), reply.body.Bytes()...),
+		CreatedAt:   now,
+		ExpiresAt:   now.Add(ctx.cacheTTL),
+	}
+	for k, v := range reply.Hdr {
+		if isUncachedHeader(k) {
+			continue
+		}
+		cr.Header[k] = append([]string(nil), v...)
+	}
+
+	key := ctx.cacheKey
+	if len(vary) > 0 {
+		if err := appCacheStore.Put(key, &CachedReply{Vary: vary, CreatedAt: now, ExpiresAt: cr.ExpiresAt}); err != nil {
+			ctx.Log().Errorf("cache: %s on %s", err, ctx.Req.Path)
+			return
+		}
+		key = varyCacheKey(ctx, key, vary)
+	}
+
+	if err := appCacheStore.Put(key, cr); err != nil {
+		ctx.Log().Errorf("cache: %s on %s", err, ctx.Req.Path)
+	}
+}
+
+// varyHeaderNames method returns the canonical header names of the reply
+// `Vary` header.
+func varyHeaderNames(hdr http.Header) []string {
+	var names []string
+	for _, value := range hdr[ahttp.HeaderVary] {
+		for _, name := range strings.Split(value, ",") {
+			if name = strings.TrimSpace(name); !ess.IsStrEmpty(name) {
+				names = append(names, http.CanonicalHeaderKey(name))
+			}
+		}
+	}
+	return names
+}
+
+// varyCacheKey method returns the response cache key of the reply variant,
+// composed of given request key and request values of the vary headers.
+func varyCacheKey(ctx *Context, key string, vary []string) string {
+	buf := bytes.NewBufferString(key)
+	for _, name := range vary {
+		buf.WriteByte(0)
+		buf.WriteString(name + ":" + strings.Join(ctx.Req.Header[name], ","))
+	}
+	return buf.String()
+}
+
+// isUncachedHeader method returns true if the reply header is specific to
+// the request, i.e. request ID and `Set-Cookie`, it's not stored in cache.
+func isUncachedHeader(name string) bool {
+	name = http.CanonicalHeaderKey(name)
+	return name == http.CanonicalHeaderKey(appRequestIDHeader) || name == headerSetCookie
+}
+
+// responseCacheKey method returns the response cache key composed of
+// request path, host, method, query params, vary headers and locale. Query
+// params and vary headers can be configured per route via `cache.query_params`
+// and `cache.vary` in routes.conf, by default all query params are used.
+// `Accept` header is always part of the key and reply `Vary` headers are
+// added on top of it, refer `storeCache`.
+func responseCacheKey(ctx *Context) string {
+	buf := &bytes.Buffer{}
+	buf.WriteString(ctx.Req.Path)
+	buf.WriteByte(0)
+	buf.WriteString(ctx.Req.Host)
+	buf.WriteByte(0)
+	buf.WriteString(ctx.Req.Method)
+	buf.WriteByte(0)
+
+	query := ctx.Req.Raw.URL.Query()
+	if names := routeStringList(ctx, "cache.query_params", nil); names != nil {
+		selected := url.Values{}
+		for _, name := range names {
+			if values, found := query[name]; found {
+				selected[name] = values
+			}
+		}
+		query = selected
+	}
+	buf.WriteString(query.Encode())
+
+	vary := append([]string{ahttp.HeaderAccept}, routeStringList(ctx, "cache.vary", nil)...)
+	for _, name := range vary {
+		buf.WriteByte(0)
+		buf.WriteString(name + ":" + ctx.Req.Header.Get(name))
+	}
+
+	if ctx.Req.Locale != nil {
+		buf.WriteByte(0)
+		buf.WriteString(ctx.Req.Locale.String())
+	}
+
+	return buf.String()
+}
+
+// writeCachedReply method writes the cached reply on the wire.
+func (e *engine) writeCachedReply(ctx *Context) {
+	reply, cr := ctx.Reply(), ctx.cachedReply
+	reply.Code = cr.Code
+	reply.ContType = cr.ContentType
+	for k, v := range cr.Header {
+		reply.Hdr[k] = append([]string(nil), v...)
+	}
+	reply.Header(headerAge, strconv.Itoa(int(time.Since(cr.CreatedAt).Seconds())))
+
+	reply.body = e.getBuffer()
+	_, _ = reply.body.Write(cr.Body)
+
+	// ETag, reply 304 if it matches the `If-None-Match`
+	e.writeETag(ctx)
+
+	// HTTP headers, cookies and status
+	e.writeStatus(ctx, reply.body.Len() != 0)
+
+	// Write response buffer on the wire
+	_, _ = reply.body.WriteTo(ctx.Res)
+
+	// 'OnAfterReply' server extension point
+	publishOnAfterReplyEvent(ctx)
+}
@@ -0,0 +1,383 @@
+// Copyright (c) Jeevanandam M. (https://github.com/jeevatkm)
+// go-aah/aah source code and usage is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package aah
+
+import (
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"aahframework.org/ahttp.v0"
+	"aahframework.org/config.v0"
+	"aahframework.org/router.v0"
+	"aahframework.org/test.v0/assert"
+)
+
+func TestCacheMemoryStore(t *testing.T) {
+	store := NewMemoryCacheStore(2)
+	newReply := func(body string, ttl time.Duration) *CachedReply {
+		return &CachedReply{Code: http.StatusOK, Body: []byte(body), ExpiresAt: time.Now().Add(ttl)}
+	}
+
+	assert.Nil(t, store.Put("/users\x00a", newReply("a", time.Minute)))
+	assert.Nil(t, store.Put("/users\x00b", newReply("b", time.Minute)))
+	assert.Equal(t, "a", string(store.Get("/users\x00a").Body))
+
+	// least recently used 'b' is evicted
+	assert.Nil(t, store.Put("/products\x00c", newReply("c", time.Minute)))
+	assert.Equal(t, 2, store.Len())
+	assert.Nil(t, store.Get("/users\x00b"))
+	assert.NotNil(t, store.Get("/products\x00c"))
+
+	// update
+	assert.Nil(t, store.Put("/products\x00c", newReply("c2", time.Minute)))
+	assert.Equal(t, "c2", string(store.Get("/products\x00c").Body))
+
+	// purge by prefix
+	assert.Nil(t, store.Purge("/users\x00"))
+	assert.Nil(t, store.Get("/users\x00a"))
+	assert.Equal(t, 1, store.Len())
+
+	// expired
+	assert.Nil(t, store.Put("/orders\x00d", newReply("d", -time.Second)))
+	assert.Nil(t, store.Get("/orders\x00d"))
+
+	assert.Nil(t, store.Delete("/products\x00c"))
+	assert.Equal(t, 0, store.Len())
+}
+
+func TestCacheFileStore(t *testing.T) {
+	dir, _ := ioutil.TempDir("", "aah-cache")
+	defer func() { _ = os.RemoveAll(dir) }()
+
+	store, err := NewFileCacheStore(filepath.Join(dir, "replies"))
+	assert.FailNowOnError(t, err, "")
+
+	cr := &CachedReply{
+		Code:        http.StatusOK,
+		ContentType: ahttp.ContentTypeJSON.Raw(),
+		Header:      http.Header{"X-Request-Id": []string{"1001"}},
+		Body:        []byte(`{"id":1001}`),
+		ExpiresAt:   time.Now().Add(time.Minute),
+	}
+	assert.Nil(t, store.Put("/users/1001\x00localhost", cr))
+	assert.Nil(t, store.Put("/users/1002\x00localhost", &CachedReply{ExpiresAt: time.Now().Add(time.Minute)}))
+
+	cached := store.Get("/users/1001\x00localhost")
+	assert.NotNil(t, cached)
+	assert.Equal(t, `{"id":1001}`, string(cached.Body))
+	assert.Equal(t, "1001", cached.Header.Get("X-Request-Id"))
+	assert.Nil(t, store.Get("/users/1003\x00localhost"))
+
+	assert.Nil(t, store.Purge("/users/1001\x00"))
+	assert.Nil(t, store.Get("/users/1001\x00localhost"))
+	assert.NotNil(t, store.Get("/users/1002\x00localhost"))
+
+	assert.Nil(t, store.Purge(""))
+	assert.Nil(t, store.Get("/users/1002\x00localhost"))
+
+	// expired
+	assert.Nil(t, store.Put("/users/1004\x00localhost", &CachedReply{ExpiresAt: time.Now().Add(-time.Second)}))
+	assert.Nil(t, store.Get("/users/1004\x00localhost"))
+	files, _ := filepath.Glob(filepath.Join(dir, "replies", "*"+cacheFileExt))
+	assert.Equal(t, 0, len(files))
+
+	fi, _ := os.Stat(filepath.Join(dir, "replies"))
+	assert.Equal(t, os.FileMode(0700), fi.Mode().Perm())
+}
+
+func TestCacheInit(t *testing.T) {
+	defer func() {
+		appCacheStore, appRoutesConfig, appRouteConfigPaths = nil, nil, nil
+		appRouteCacheTTLs = make(map[string]time.Duration)
+	}()
+
+	appRoutesConfig, _ = config.ParseString("")
+	appRoutesConfig.SetBool("domains.localhost.routes.products.cache.enable", true)
+	appRoutesConfig.SetString("domains.localhost.routes.products.cache.ttl", "30s")
+	appRoutesConfig.SetBool("domains.localhost.routes.orders.cache.enable", true)
+	appRouteConfigPaths = map[string]string{
+		routeConfigKey("localhost", "products"): "domains.localhost.routes.products",
+		routeConfigKey("localhost", "orders"):   "domains.localhost.routes.orders",
+		routeConfigKey("localhost", "users"):    "domains.localhost.routes.users",
+	}
+
+	cfg, _ := config.ParseString("")
+	assert.Nil(t, initResponseCache(cfg))
+	_, ok := appCacheStore.(*MemoryCacheStore)
+	assert.True(t, ok)
+	assert.Equal(t, 5*time.Minute, appCacheTTL)
+	assert.Equal(t, 30*time.Second, appRouteCacheTTLs["domains.localhost.routes.products"])
+	assert.Equal(t, 5*time.Minute, appRouteCacheTTLs["domains.localhost.routes.orders"])
+	assert.Equal(t, 2, len(appRouteCacheTTLs))
+
+	// file store directory accessible by others
+	dir, _ := ioutil.TempDir("", "aah-cache")
+	defer func() { _ = os.RemoveAll(dir) }()
+	_ = os.Chmod(dir, 0755)
+	appCacheStore = nil
+	cfg.SetString("cache.response.store", "file")
+	cfg.SetString("cache.response.file.dir", dir)
+	assert.Equal(t, "'cache.response.file.dir' "+dir+" must be accessible only by the owner", initResponseCache(cfg).Error())
+	assert.Nil(t, appCacheStore)
+
+	_ = os.Chmod(dir, 0700)
+	assert.Nil(t, initResponseCache(cfg))
+	_, ok = appCacheStore.(*FileCacheStore)
+	assert.True(t, ok)
+
+	// errors
+	appCacheStore = nil
+	cfg.SetString("cache.response.store", "redis")
+	assert.Equal(t, "'cache.response.store' value 'redis' is not supported", initResponseCache(cfg).Error())
+
+	cfg.SetString("cache.response.ttl", "5 minutes")
+	assert.NotNil(t, initResponseCache(cfg))
+
+	cfg.SetString("cache.response.ttl", "1m")
+	cfg.SetString("cache.response.store", "memory")
+	appRoutesConfig.SetString("domains.localhost.routes.orders.cache.ttl", "1 hour")
+	assert.NotNil(t, initResponseCache(cfg))
+}
+
+func TestCacheWriteReply(t *testing.T) {
+	appConfig, _ = config.ParseString("")
+	err := initSecurity(filepath.Join(getTestdataPath(), appConfigDir()), appConfig)
+	assert.FailNowOnError(t, err, "")
+	e := newEngine(appConfig)
+
+	appRoutesConfig, _ = config.ParseString(`
+	domains {
+		localhost {
+			routes {
+				products {
+					cache {
+						enable = true
+						query_params = ["page"]
+					}
+				}
+			}
+		}
+	}`)
+	appRouteConfigPaths = map[string]string{
+		routeConfigKey("localhost", "products"): "domains.localhost.routes.products",
+	}
+	defer func() {
+		appCacheStore, appRoutesConfig, appRouteConfigPaths = nil, nil, nil
+		appRouteCacheTTLs = make(map[string]time.Duration)
+	}()
+	assert.Nil(t, initResponseCache(appConfig))
+
+	calls := 0
+	serve := func(target string, hdrs map[string]string, action func(ctx *Context)) *httptest.ResponseRecorder {
+		req := httptest.NewRequest("GET", target, nil)
+		req.Header.Set(ahttp.HeaderAccept, ahttp.ContentTypeJSON.Raw())
+		for k, v := range hdrs {
+			req.Header.Set(k, v)
+		}
+		w := httptest.NewRecorder()
+		ctx := e.prepareContext(w, req)
+		e.setRequestID(ctx)
+		ctx.domain = &router.Domain{Host: "localhost"}
+		ctx.route = &router.Route{Name: "products"}
+		if !checkCache(ctx, routeCacheTTL(ctx)) {
+			calls++
+			action(ctx)
+		}
+		e.writeReply(ctx)
+		return w
+	}
+
+	list := func(ctx *Context) { ctx.Reply().Header("X-Total", "2").JSON(Data{"calls": calls}) }
+
+	w := serve("http://localhost:8080/products?page=1&sort=asc", map[string]string{ahttp.HeaderXRequestID: "req-1"}, list)
+	assert.Equal(t, `{"calls":1}`, w.Body.String())
+	assert.Equal(t, "req-1", w.Header().Get(ahttp.HeaderXRequestID))
+
+	// cached, non-selected query param is ignored
+	w = serve("http://localhost:8080/products?sort=desc&page=1", map[string]string{ahttp.HeaderXRequestID: "req-2"}, list)
+	assert.Equal(t, 1, calls)
+	assert.Equal(t, []string{"req-2"}, w.Header()[ahttp.HeaderXRequestID])
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, `{"calls":1}`, w.Body.String())
+	assert.Equal(t, ahttp.ContentTypeJSON.Raw(), w.Header().Get(ahttp.HeaderContentType))
+	assert.Equal(t, "2", w.Header().Get("X-Total"))
+	assert.Equal(t, "0", w.Header().Get(headerAge))
+
+	// selected query param is part of the key
+	w = serve("http://localhost:8080/products?page=2", nil, list)
+	assert.Equal(t, `{"calls":2}`, w.Body.String())
+
+	// bypass
+	w = serve("http://localhost:8080/products?page=1", map[string]string{headerCacheControl: "no-cache"}, list)
+	assert.Equal(t, `{"calls":3}`, w.Body.String())
+	w = serve("http://localhost:8080/products?page=1", nil, list)
+	assert.Equal(t, `{"calls":3}`, w.Body.String())
+
+	// purge
+	assert.Nil(t, PurgeCache("/products"))
+	w = serve("http://localhost:8080/products?page=1", nil, list)
+	assert.Equal(t, `{"calls":4}`, w.Body.String())
+
+	// reply variants of the request headers
+	variant := func(ctx *Context) {
+		ctx.Reply().Header(ahttp.HeaderVary, "X-Tenant").JSON(Data{"calls": calls})
+	}
+	w = serve("http://localhost:8080/products?page=5", map[string]string{"X-Tenant": "acme"}, variant)
+	assert.Equal(t, `{"calls":5}`, w.Body.String())
+	w = serve("http://localhost:8080/products?page=5", map[string]string{"X-Tenant": "globex"}, variant)
+	assert.Equal(t, `{"calls":6}`, w.Body.String())
+	w = serve("http://localhost:8080/products?page=5", map[string]string{"X-Tenant": "acme"}, variant)
+	assert.Equal(t, `{"calls":5}`, w.Body.String())
+	w = serve("http://localhost:8080/products?page=5", map[string]string{ahttp.HeaderAccept: ahttp.ContentTypeXML.Raw()}, variant)
+	assert.Equal(t, `{"calls":7}`, w.Body.String())
+
+	// replies with cookies, disabled cache, non 200 and `Vary: *` are not cached
+	assert.Nil(t, PurgeCache(""))
+	_ = serve("http://localhost:8080/products", nil, func(ctx *Context) {
+		ctx.Reply().Cookie(&http.Cookie{Name: "visited", Value: "true"}).JSON(Data{})
+	})
+	_ = serve("http://localhost:8080/products?page=3", nil, func(ctx *Context) {
+		ctx.Reply().DisableCache().JSON(Data{})
+	})
+	_ = serve("http://localhost:8080/products?page=4", nil, func(ctx *Context) {
+		ctx.Reply().NotFound().JSON(Data{})
+	})
+	_ = serve("http://localhost:8080/products?page=5", nil, func(ctx *Context) {
+		ctx.Reply().Header(ahttp.HeaderVary, "*").JSON(Data{})
+	})
+	assert.Equal(t, 0, appCacheStore.(*MemoryCacheStore).Len())
+}
+
+func TestCacheMiddleware(t *testing.T) {
+	appCacheStore = NewMemoryCacheStore(10)
+	defer func() { appCacheStore = nil }()
+
+	newCtx := func(method string) *Context {
+		req := httptest.NewRequest(method, "http://localhost:8080/news", nil)
+		return &Context{Req: ahttp.ParseRequest(req, &ahttp.Request{}), reply: NewReply()}
+	}
+
+	calls := 0
+	mw := &Middleware{next: func(ctx *Context, m *Middleware) { calls++ }}
+	cacheMw := CacheMiddleware(time.Minute)
+
+	ctx := newCtx(ahttp.MethodGet)
+	cacheMw(ctx, mw)
+	assert.Equal(t, 1, calls)
+	assert.Equal(t, time.Minute, ctx.cacheTTL)
+	assert.Nil(t, ctx.cachedReply)
+
+	_ = appCacheStore.Put(ctx.cacheKey, &CachedReply{Code: http.StatusOK, ExpiresAt: time.Now().Add(time.Minute)})
+	ctx = newCtx(ahttp.MethodGet)
+	cacheMw(ctx, mw)
+	assert.Equal(t, 1, calls)
+	assert.True(t, ctx.abort)
+	assert.NotNil(t, ctx.cachedReply)
+
+	// not applicable for POST
+	ctx = newCtx(ahttp.MethodPost)
+	cacheMw(ctx, mw)
+	assert.Equal(t, 2, calls)
+	assert.Equal(t, time.Duration(0), ctx.cacheTTL)
+
+	// user specific requests are not cached by default
+	ctx = newCtx(ahttp.MethodGet)
+	ctx.Req.Header.Set(ahttp.HeaderAuthorization, "Bearer token")
+	cacheMw(ctx, mw)
+	assert.Equal(t, 3, calls)
+	assert.Equal(t, time.Duration(0), ctx.cacheTTL)
+
+	ctx = newCtx(ahttp.MethodGet)
+	ctx.Req.Raw.AddCookie(&http.Cookie{Name: "aah_session", Value: "session-value"})
+	cacheMw(ctx, mw)
+	assert.Equal(t, 4, calls)
+	assert.Nil(t, ctx.cachedReply)
+
+	appCacheCredentialed = true
+	defer func() { appCacheCredentialed = false }()
+	ctx = newCtx(ahttp.MethodGet)
+	ctx.Req.Header.Set(ahttp.HeaderAuthorization, "Bearer token")
+	cacheMw(ctx, mw)
+	assert.Equal(t, 4, calls)
+	assert.NotNil(t, ctx.cachedReply)
+}
+
+func TestCacheAfterAuthentication(t *testing.T) {
+	oldStack := mwStack
+	mwStack = []MiddlewareFunc{testAuthMiddleware, interceptorMiddleware, actionMiddleware}
+	invalidateMwChain()
+	defer func() {
+		mwStack = oldStack
+		invalidateMwChain()
+	}()
+
+	appConfig, _ = config.ParseString("")
+	err := initSecurity(filepath.Join(getTestdataPath(), appConfigDir()), appConfig)
+	assert.FailNowOnError(t, err, "")
+	e := newEngine(appConfig)
+
+	appRoutesConfig, _ = config.ParseString(`
+	domains {
+		localhost {
+			routes {
+				credits {
+					cache {
+						enable = true
+					}
+				}
+			}
+		}
+	}`)
+	appRouteConfigPaths = map[string]string{
+		routeConfigKey("localhost", "credits"): "domains.localhost.routes.credits",
+	}
+	defer func() {
+		appCacheStore, appRoutesConfig, appRouteConfigPaths = nil, nil, nil
+		appRouteCacheTTLs = make(map[string]time.Duration)
+	}()
+	assert.Nil(t, initResponseCache(appConfig))
+
+	serve := func(apiKey string) (*Context, *httptest.ResponseRecorder) {
+		req := httptest.NewRequest("GET", "http://localhost:8080/credits", nil)
+		req.Header.Set("X-Api-Key", apiKey)
+		w := httptest.NewRecorder()
+		ctx := e.prepareContext(w, req)
+		ctx.domain = &router.Domain{Host: "localhost"}
+		ctx.route = &router.Route{Name: "credits"}
+		ctx.controller, ctx.action = "Site", &MethodInfo{Name: "Credits"}
+		ctx.target = &Site{Context: ctx}
+		e.executeMiddlewares(ctx)
+		e.writeReply(ctx)
+		return ctx, w
+	}
+
+	ctx, w := serve("secret")
+	assert.Nil(t, ctx.cachedReply)
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	// cached reply is not served to unauthenticated request
+	ctx, w = serve("")
+	assert.Nil(t, ctx.cachedReply)
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+
+	ctx, w = serve("secret")
+	assert.NotNil(t, ctx.cachedReply)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "custom value", w.Header().Get("X-Custom-Header"))
+}
+
+func testAuthMiddleware(ctx *Context, m *Middleware) {
+	if ctx.Req.Header.Get("X-Api-Key") != "secret" {
+		ctx.Reply().Unauthorized().Text("401 Unauthorized")
+		ctx.Abort()
+		return
+	}
+	m.Next(ctx)
+}
@@ -5,10 +5,14 @@
 package aah
 
 import (
+	"context"
 	"errors"
+	"io"
+	"net/http"
 	"net/url"
 	"reflect"
 	"strings"
+	"time"
 
 	"aahframework.org/ahttp.v0"
 	"aahframework.org/essentials.v0"
@@ -47,8 +51,19 @@ type (
 		session    *session.Session
 		reply      *Reply
 		viewArgs   map[string]interface{}
+		values     map[string]interface{}
 		abort      bool
 		decorated  bool
+		startTime  time.Time
+		span       *Span
+		rootSpan   *Span
+		logger     *RequestLogger
+		stages     [stageCount]time.Duration
+
+		validationErrors []*ValidationError
+		cacheTTL         time.Duration
+		cacheKey         string
+		cachedReply      *CachedReply
 	}
 )
 
@@ -109,6 +124,184 @@ func (ctx *Context) Session() *session.Session {
 	return ctx.session
 }
 
+// ValidationErrors method returns the validation errors of bound action
+// parameters otherwise nil. Framework replies the validation errors with
+// HTTP Bad Request (Status 400) unless route opts out of automatic rejection
+// via `validation.auto_reject = false`. Validation errors also made
+// available to templates via `ValidationErrors` view arg.
+func (ctx *Context) ValidationErrors() []*ValidationError {
+	return ctx.validationErrors
+}
+
+// Bind method binds the request into given struct pointer, decoder is chosen
+// based on request Content-Type; JSON and XML payload otherwise request
+// params (Path, Form and Query). In strict mode unknown fields are rejected,
+// enable it via aah.conf `request.bind.strict = true` or per route
+// `bind.strict = true` in routes.conf.
+//
+// On binding failure framework replies HTTP Bad Request (Status 400) with
+// `BindError`, which holds the offending field. Action can just return.
+//		For Example:
+//
+//		var user models.User
+//		if err := u.Bind(&user); err != nil {
+//			return
+//		}
+func (ctx *Context) Bind(v interface{}) error {
+	switch contentType := ctx.Req.ContentType.Mime; {
+	case isJSONContentType(contentType):
+		return ctx.BindJSON(v)
+	case isXMLContentType(contentType):
+		return ctx.BindXML(v)
+	}
+	return ctx.BindForm(v)
+}
+
+// BindJSON method decodes the request JSON payload into given value,
+// refer `Context.Bind`.
+func (ctx *Context) BindJSON(v interface{}) error {
+	return ctx.bind(v, decodeJSON)
+}
+
+// BindXML method decodes the request XML payload into given value,
+// refer `Context.Bind`. Strict mode is not applicable for XML.
+func (ctx *Context) BindXML(v interface{}) error {
+	return ctx.bind(v, decodeXML)
+}
+
+// BindForm method binds the request params (Path, Form and Query) into given
+// struct pointer, field key is taken from the tag `bind` otherwise field
+// name. In strict mode Form params are checked for unknown fields, refer
+// `Context.Bind`.
+func (ctx *Context) BindForm(v interface{}) error {
+	return ctx.bind(v, decodeForm)
+}
+
+// Context method returns the request `context.Context`, it carries the
+// deadline of request handling timeout `request.timeout` from aah.conf or
+// route `timeout` from routes.conf. Pass it to the downstream calls such as
+// DB queries and HTTP requests, so they are cancelled when request handling
+// times out or client goes away.
+func (ctx *Context) Context() context.Context {
+	return ctx.Req.Raw.Context()
+}
+
+// WithContext method sets the given `context.Context` on the request, such as
+// derived context with values or cancellation. It's returned by
+// `Context.Context` for the rest of request handling. Chained call is possible.
+func (ctx *Context) WithContext(c context.Context) *Context {
+	if c != nil {
+		ctx.Req.Raw = ctx.Req.Raw.WithContext(c)
+	}
+	return ctx
+}
+
+// Set method stores the given value for the key in the request-scoped
+// storage, it's handy to hand over the values between middlewares,
+// interceptors and actions. Storage is cleared once the request is completed.
+//		For Example:
+//
+//		// auth middleware
+//		ctx.Set("principal", user)
+//
+//		// action
+//		user := c.Get("principal").(*models.User)
+func (ctx *Context) Set(key string, value interface{}) {
+	if ctx.values == nil {
+		ctx.values = make(map[string]interface{})
+	}
+	ctx.values[key] = value
+}
+
+// Get method returns the value for the key from the request-scoped storage
+// otherwise nil.
+func (ctx *Context) Get(key string) interface{} {
+	return ctx.values[key]
+}
+
+// GetString method returns the string value for the key from the
+// request-scoped storage otherwise empty string.
+func (ctx *Context) GetString(key string) string {
+	value, _ := ctx.values[key].(string)
+	return value
+}
+
+// GetInt method returns the int value for the key from the request-scoped
+// storage otherwise zero.
+func (ctx *Context) GetInt(key string) int {
+	value, _ := ctx.values[key].(int)
+	return value
+}
+
+// GetBool method returns the bool value for the key from the request-scoped
+// storage otherwise false.
+func (ctx *Context) GetBool(key string) bool {
+	value, _ := ctx.values[key].(bool)
+	return value
+}
+
+// IsExists method returns true if the key exists in the request-scoped
+// storage otherwise false.
+func (ctx *Context) IsExists(key string) bool {
+	_, found := ctx.values[key]
+	return found
+}
+
+// Log method returns the request-scoped logger, it stamps the request ID,
+// route name, controller action and client IP on every log entry. It's handy
+// to correlate the log entries of the request.
+//		For Example:
+//
+//		c.Log().Infof("User created: %s", user.ID)
+//
+//		// reqid=5946ed129bf23409520736de route=create_user action=User.Create clientip=10.0.0.1 User created: 1001
+func (ctx *Context) Log() *RequestLogger {
+	if ctx.logger == nil {
+		ctx.logger = &RequestLogger{ctx: ctx}
+	}
+	return ctx.logger
+}
+
+// Span method returns the current span of request tracing, it's nil if
+// tracing is disabled via `server.tracing.enable`.
+func (ctx *Context) Span() *Span {
+	return ctx.span
+}
+
+// StartSpan method starts the child span of the current span for the
+// application operation, end it via `Span.End`. It returns nil if tracing is
+// disabled, span methods are no-op on nil.
+//		For Example:
+//
+//		span := ctx.StartSpan("db.query")
+//		defer span.End()
+func (ctx *Context) StartSpan(name string) *Span {
+	if ctx.span == nil {
+		return nil
+	}
+	return ctx.span.child(name)
+}
+
+// InjectTraceparent method sets the W3C `traceparent` header of the current
+// span into given header, so the trace is continued by downstream service.
+//		For Example:
+//
+//		req, _ := http.NewRequest("GET", "http://inventory/items", nil)
+//		ctx.InjectTraceparent(req.Header)
+func (ctx *Context) InjectTraceparent(hdr http.Header) {
+	if ctx.span != nil {
+		hdr.Set(HeaderTraceparent, ctx.span.Traceparent())
+	}
+}
+
+// Body method returns the request body reader, it is limited to the
+// `max_body_size`. For streaming body route `stream_body = true` framework
+// does not parse the request body, read it in the action; such as large
+// uploads. Reading beyond the limit returns an error.
+func (ctx *Context) Body() io.ReadCloser {
+	return ctx.Req.Raw.Body
+}
+
 // Abort method sets the abort to true. It means framework will not proceed with
 // next middleware, next interceptor or action based on context it being used.
 // Contexts:
@@ -193,8 +386,18 @@ func (ctx *Context) Reset() {
 	ctx.session = nil
 	ctx.reply = nil
 	ctx.viewArgs = nil
+	ctx.values = nil
 	ctx.abort = false
 	ctx.decorated = false
+	ctx.startTime = time.Time{}
+	ctx.span = nil
+	ctx.rootSpan = nil
+	ctx.logger = nil
+	ctx.stages = [stageCount]time.Duration{}
+	ctx.validationErrors = nil
+	ctx.cacheTTL = 0
+	ctx.cacheKey = ""
+	ctx.cachedReply = nil
 }
 
 //‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾
@@ -226,6 +429,22 @@ func (ctx *Context) setTarget(route *router.Route) error {
 	return nil
 }
 
+// bind method binds the request into given value via decoder, it replies
+// HTTP Bad Request (Status 400) on binding failure.
+func (ctx *Context) bind(v interface{}, decode func(ctx *Context, v interface{}) error) error {
+	if rv := reflect.ValueOf(v); rv.Kind() != reflect.Ptr || rv.IsNil() {
+		err := errors.New("bind: value must be a non-nil pointer")
+		handleError(ctx, &HTTPError{Code: http.StatusInternalServerError, Err: err})
+		return err
+	}
+
+	if err := decode(ctx, v); err != nil {
+		replyBindError(ctx, err)
+		return err
+	}
+	return nil
+}
+
 //‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾
 // Unexported methods
 //___________________________________
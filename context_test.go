@@ -5,6 +5,7 @@
 package aah
 
 import (
+	"context"
 	"net/http/httptest"
 	"path/filepath"
 	"reflect"
@@ -42,6 +43,12 @@ type (
 		Level4
 		Func1
 	}
+
+	testPrincipal struct {
+		Username string
+	}
+
+	testContextKey string
 )
 
 func TestContextReverseURL(t *testing.T) {
@@ -81,6 +88,49 @@ func TestContextViewArgs(t *testing.T) {
 	assert.Nil(t, ctx.viewArgs["notexists"])
 }
 
+func TestContextValues(t *testing.T) {
+	ctx := &Context{}
+	assert.Nil(t, ctx.Get("principal"))
+	assert.False(t, ctx.IsExists("principal"))
+
+	ctx.Set("principal", &testPrincipal{Username: "jeeva"})
+	ctx.Set("tenant", "aah")
+	ctx.Set("attempts", 3)
+	ctx.Set("verified", true)
+
+	assert.Equal(t, "jeeva", ctx.Get("principal").(*testPrincipal).Username)
+	assert.True(t, ctx.IsExists("principal"))
+	assert.Equal(t, "aah", ctx.GetString("tenant"))
+	assert.Equal(t, 3, ctx.GetInt("attempts"))
+	assert.True(t, ctx.GetBool("verified"))
+
+	// mismatched type returns zero value
+	assert.Equal(t, "", ctx.GetString("attempts"))
+	assert.Equal(t, 0, ctx.GetInt("tenant"))
+	assert.False(t, ctx.GetBool("tenant"))
+
+	ctx.Reset()
+	assert.Nil(t, ctx.values)
+	assert.Nil(t, ctx.Get("principal"))
+}
+
+func TestContextWithContext(t *testing.T) {
+	ctx := &Context{Req: getAahRequest("GET", "http://localhost:8080/users", "")}
+	assert.Equal(t, context.Background(), ctx.Context())
+
+	c, cancel := context.WithCancel(context.WithValue(ctx.Context(), testContextKey("tenant"), "aah"))
+	assert.Equal(t, ctx, ctx.WithContext(c))
+	assert.Equal(t, "aah", ctx.Context().Value(testContextKey("tenant")))
+	assert.Nil(t, ctx.Context().Err())
+
+	cancel()
+	assert.Equal(t, context.Canceled, ctx.Context().Err())
+
+	// nil is ignored
+	ctx.WithContext(nil)
+	assert.Equal(t, c, ctx.Context())
+}
+
 func TestContextMsg(t *testing.T) {
 	i18nDir := filepath.Join(getTestdataPath(), appI18nDir())
 	err := initI18n(i18nDir)
@@ -47,6 +47,8 @@ type (
 	}
 
 	// ParameterInfo holds information of single parameter in the method.
+	// `Type` is the declared type of the parameter, e.g. `*models.User`,
+	// it's not dereferenced.
 	ParameterInfo struct {
 		Name string
 		Type reflect.Type
@@ -59,14 +61,14 @@ type (
 
 // AddController method adds given controller into controller registory.
 // with "dereferenced" a.k.a "indirecting".
+//
+// Note: Action parameter type is kept as declared, since it's used for auto
+// binding of request values into action arguments.
 func AddController(c interface{}, methods []*MethodInfo) {
 	cType := actualType(c)
 
 	methodMapping := map[string]*MethodInfo{}
 	for _, method := range methods {
-		for _, param := range method.Parameters {
-			param.Type = actualType(param.Type)
-		}
 		methodMapping[strings.ToLower(method.Name)] = method
 	}
 
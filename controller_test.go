@@ -5,6 +5,7 @@
 package aah
 
 import (
+	"reflect"
 	"testing"
 
 	"aahframework.org/router.v0"
@@ -47,3 +48,12 @@ func TestFindMethodController(t *testing.T) {
 	mi = ci.FindMethod("NoMethodExists")
 	assert.Nil(t, mi)
 }
+
+func TestControllerParameterType(t *testing.T) {
+	addToCRegistry()
+
+	// declared type is kept as-is, it's not dereferenced
+	mi := cRegistry.Lookup(&router.Route{Controller: "Level3"}).FindMethod("Testing")
+	assert.Equal(t, reflect.TypeOf((*int)(nil)), mi.Parameters[0].Type)
+	assert.Equal(t, "*int", mi.Parameters[0].Type.String())
+}
@@ -6,16 +6,18 @@ package aah
 
 import (
 	"bytes"
+	"context"
 	"errors"
 	"fmt"
+	"io"
 	"net/http"
 	"os"
+	"time"
 
 	"aahframework.org/ahttp.v0"
 	"aahframework.org/aruntime.v0"
 	"aahframework.org/config.v0"
 	"aahframework.org/essentials.v0"
-	"aahframework.org/log.v0"
 	"aahframework.org/pool.v0"
 )
 
@@ -28,13 +30,15 @@ const (
 	aahServerName         = "aah-go-server"
 	gzipContentEncoding   = "gzip"
 	hstsHeaderValue       = "max-age=31536000; includeSubDomains"
+	headerETag            = "ETag"
 	defaultGlobalPoolSize = 500
 	defaultBufPoolSize    = 200
 )
 
 var (
-	errFileNotFound   = errors.New("file not found")
-	noGzipStatusCodes = []int{http.StatusNotModified, http.StatusNoContent}
+	errFileNotFound    = errors.New("file not found")
+	noGzipStatusCodes  = []int{http.StatusNotModified, http.StatusNoContent}
+	appRequestIDHeader = ahttp.HeaderXRequestID
 )
 
 type (
@@ -47,12 +51,24 @@ type (
 		isRequestIDEnabled bool
 		requestIDHeader    string
 		isGzipEnabled      bool
+		isStreamEnabled    bool
+		isETagEnabled      bool
+		isWeakETag         bool
+		contentTypes       []string
 		ctxPool            *pool.Pool
 		reqPool            *pool.Pool
 		replyPool          *pool.Pool
 		bufPool            *pool.Pool
 	}
 
+	// streamWriter writes the HTTP headers, cookies and status on first write
+	// then response body directly on the wire.
+	streamWriter struct {
+		e           *engine
+		ctx         *Context
+		wroteHeader bool
+	}
+
 	byName []os.FileInfo
 )
 
@@ -62,12 +78,41 @@ type (
 
 // ServeHTTP method implementation of http.Handler interface.
 func (e *engine) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	// Metrics on aah server path
+	if isMetricsRequest(r) {
+		appMetrics.ServeHTTP(w, r)
+		return
+	}
+
+	// Health and readiness on aah server path
+	if isHealthRequest(r) {
+		appHealth.ServeHTTP(w, r)
+		return
+	}
+
+	metricsRequestStart()
+	defer metricsRequestEnd()
+
 	ctx := e.prepareContext(w, r)
-	defer e.putContext(ctx)
+
+	// Context is released by the action goroutine if request handling is
+	// timed out, refer `executeMiddlewaresWithTimeout`.
+	released := false
+	defer func() {
+		if !released {
+			endRequestSpan(ctx)
+			metricsObserveRequest(ctx)
+			checkSlowRequest(ctx)
+			e.putContext(ctx)
+		}
+	}()
 
 	// Recovery handling, capture every possible panic(s)
 	defer e.handleRecovery(ctx)
 
+	// Request tracing, trace is continued from `traceparent` header
+	startRequestSpan(ctx)
+
 	if e.isRequestIDEnabled {
 		e.setRequestID(ctx)
 	}
@@ -84,13 +129,25 @@ func (e *engine) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 	e.loadSession(ctx)
 
 	// Parsing request params
-	e.parseRequestParams(ctx)
+	if err := e.parseRequestParams(ctx); err == errRequestEntityTooLarge {
+		ctx.Log().Warnf("Request Entity Too Large on %s, allowed size: %d bytes", ctx.Req.Path, requestMaxBodyBytes(ctx))
+		handleError(ctx, &HTTPError{Code: http.StatusRequestEntityTooLarge, Err: err})
+		e.writeReply(ctx)
+		return
+	}
 
 	// Set defaults when actual value not found
 	e.setDefaults(ctx)
 
 	// Middlewares, interceptors, targeted controller
-	e.executeMiddlewares(ctx)
+	if timeout := routeTimeout(ctx); timeout > 0 {
+		if !e.executeMiddlewaresWithTimeout(ctx, timeout) {
+			released = true
+			return
+		}
+	} else {
+		e.executeMiddlewares(ctx)
+	}
 
 	// Write Reply on the wire
 	e.writeReply(ctx)
@@ -100,25 +157,17 @@ func (e *engine) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 // Panic gets translated into HTTP Internal Server Error (Status 500).
 func (e *engine) handleRecovery(ctx *Context) {
 	if r := recover(); r != nil {
-		log.Errorf("Internal Server Error on %s", ctx.Req.Path)
+		ctx.Log().Errorf("Internal Server Error on %s", ctx.Req.Path)
+		metricsPanic()
 
 		st := aruntime.NewStacktrace(r, AppConfig())
 		buf := e.getBuffer()
 		defer e.putBuffer(buf)
 
 		st.Print(buf)
-		log.Error(buf.String())
-
-		ctx.Reply().InternalServerError()
-		e.negotiateContentType(ctx)
-		if ahttp.ContentTypeJSON.IsEqual(ctx.Reply().ContType) {
-			ctx.Reply().JSON(Data{"code": "500", "message": "Internal Server Error"})
-		} else if ahttp.ContentTypeXML.IsEqual(ctx.Reply().ContType) {
-			ctx.Reply().XML(Data{"code": "500", "message": "Internal Server Error"})
-		} else {
-			ctx.Reply().Text("500 Internal Server Error")
-		}
+		ctx.Log().Error(buf.String())
 
+		handleError(ctx, &HTTPError{Code: http.StatusInternalServerError, Err: fmt.Errorf("%v", r)})
 		e.writeReply(ctx)
 	}
 }
@@ -128,12 +177,15 @@ func (e *engine) handleRecovery(ctx *Context) {
 func (e *engine) setRequestID(ctx *Context) {
 	if ess.IsStrEmpty(ctx.Req.Header.Get(e.requestIDHeader)) {
 		guid := ess.NewGUID()
-		log.Debugf("Request ID: %v", guid)
+		ctx.Log().Debugf("Request ID: %v", guid)
 		ctx.Req.Header.Set(e.requestIDHeader, guid)
 	} else {
-		log.Debugf("Request already has ID: %v", ctx.Req.Header.Get(e.requestIDHeader))
+		ctx.Log().Debugf("Request already has ID: %v", ctx.Req.Header.Get(e.requestIDHeader))
 	}
 	ctx.Reply().Header(e.requestIDHeader, ctx.Req.Header.Get(e.requestIDHeader))
+
+	// Request ID for the outgoing calls made with `ctx.Context()`
+	ctx.WithContext(context.WithValue(ctx.Context(), requestIDContextKey, ctx.Req.Header.Get(e.requestIDHeader)))
 }
 
 // prepareContext method gets controller, request from pool, set the targeted
@@ -144,6 +196,7 @@ func (e *engine) prepareContext(w http.ResponseWriter, req *http.Request) *Conte
 	ctx.Res = ahttp.GetResponseWriter(w)
 	ctx.reply = e.getReply()
 	ctx.viewArgs = make(map[string]interface{})
+	ctx.startTime = time.Now()
 
 	return ctx
 }
@@ -163,9 +216,11 @@ func (e *engine) prepareContext(w http.ResponseWriter, req *http.Request) *Conte
 //  - continuePipeline
 //  - notContinuePipeline
 func (e *engine) handleRoute(ctx *Context) routeStatus {
+	defer pipelineStage(ctx, "handleRoute", stageRouting)()
+
 	domain := AppRouter().FindDomain(ctx.Req)
 	if domain == nil {
-		ctx.Reply().NotFound().Text("404 Not Found")
+		handleError(ctx, &HTTPError{Code: http.StatusNotFound})
 		e.writeReply(ctx)
 		return notContinuePipeline
 	}
@@ -203,6 +258,12 @@ func (e *engine) handleRoute(ctx *Context) routeStatus {
 		return notContinuePipeline
 	}
 
+	// Request Content-Type restriction
+	if !e.checkContentType(ctx) {
+		e.writeReply(ctx)
+		return notContinuePipeline
+	}
+
 	// No controller or action found for the route
 	if err := ctx.setTarget(route); err == errTargetNotFound {
 		handleRouteNotFound(ctx, domain, route)
@@ -215,6 +276,8 @@ func (e *engine) handleRoute(ctx *Context) routeStatus {
 
 // loadSession method loads session from request for `stateful` session.
 func (e *engine) loadSession(ctx *Context) {
+	defer pipelineStage(ctx, "loadSession", stageSession)()
+
 	if AppSessionManager().IsStateful() {
 		ctx.session = AppSessionManager().GetSession(ctx.Req.Raw)
 		if ctx.session != nil {
@@ -245,17 +308,50 @@ func (e *engine) writeReply(ctx *Context) {
 	}
 
 	if reply.redirect { // handle redirects
-		log.Debugf("Redirecting to '%s' with status '%d'", reply.path, reply.Code)
+		ctx.Log().Debugf("Redirecting to '%s' with status '%d'", reply.path, reply.Code)
 		http.Redirect(ctx.Res, ctx.Req.Raw, reply.path, reply.Code)
+
+		// Request access log
+		sendToAccessLog(ctx)
+		return
+	}
+
+	// Response cache
+	if ctx.cachedReply != nil {
+		e.writeCachedReply(ctx)
+		return
+	}
+
+	// Server-Sent Events, streamed without buffering
+	if sse, ok := reply.Rdr.(*SSE); ok {
+		e.writeEventStream(ctx, sse)
+		return
+	}
+
+	// File and reader marked as seekable are served with HTTP Range and
+	// conditional requests support, Content-Type is auto-detected if not set.
+	if binary, ok := reply.Rdr.(*Binary); ok && binary.IsSeekable() &&
+		reply.Code == http.StatusOK && e.writeContent(ctx, binary) {
 		return
 	}
 
+	// Content negotiation of `Reply().Negotiate`
+	if nr, ok := reply.Rdr.(*negotiateRender); ok {
+		e.negotiateRenderer(ctx, nr)
+	}
+
 	// ContentType
 	e.negotiateContentType(ctx)
 
 	// resolving view template
 	e.resolveView(ctx)
 
+	// Streamable render writes directly on the wire, it falls back to buffered
+	// reply only if render fails before writing the response.
+	if e.isStreamable(reply.Rdr) && e.writeStream(ctx) {
+		return
+	}
+
 	// Render and detect the errors earlier, framework can write error info
 	// without messing with response.
 	// HTTP Body
@@ -267,16 +363,119 @@ func (e *engine) writeReply(ctx *Context) {
 			}
 		}
 
-		if err := reply.Rdr.Render(reply.body); err != nil {
-			log.Error("Render response body error: ", err)
-			reply.InternalServerError()
+		endRender := pipelineStage(ctx, "render", stageRender)
+		err := reply.Rdr.Render(reply.body)
+		endRender()
+		if err != nil {
+			ctx.Log().Error("Render response body error: ", err)
 			reply.body.Reset()
-			reply.body.WriteString("500 Internal Server Error\n")
+			handleError(ctx, &HTTPError{Code: http.StatusInternalServerError, Err: err})
+			if err = reply.Rdr.Render(reply.body); err != nil {
+				ctx.Log().Error("Render error reply body error: ", err)
+				reply.InternalServerError().Text("500 Internal Server Error")
+				reply.body.Reset()
+				reply.body.WriteString("500 Internal Server Error\n")
+			}
+		}
+	}
+
+	// Response cache
+	storeCache(ctx)
+
+	// ETag, reply 304 if it matches the `If-None-Match`
+	e.writeETag(ctx)
+
+	// HTTP headers, cookies and status
+	endWrite := pipelineStage(ctx, "write", stageWrite)
+	e.writeStatus(ctx, reply.body.Len() != 0)
+
+	// Write response buffer on the wire
+	_, _ = reply.body.WriteTo(ctx.Res)
+	endWrite()
+
+	// 'OnAfterReply' server extension point
+	publishOnAfterReplyEvent(ctx)
+}
+
+// writeStream method renders the reply body directly on the wire via
+// stream writer without buffering. It returns false if render fails before
+// writing anything on the wire, so error reply can be written as usual.
+func (e *engine) writeStream(ctx *Context) bool {
+	reply := ctx.Reply()
+	sw := &streamWriter{e: e, ctx: ctx}
+	endRender := pipelineStage(ctx, "render", stageRender)
+	err := reply.Rdr.Render(sw)
+	endRender()
+	if err != nil {
+		if !sw.wroteHeader {
+			ctx.Log().Error("Render response body error: ", err)
+			handleError(ctx, &HTTPError{Code: http.StatusInternalServerError, Err: err})
+			return false
 		}
+
+		// Status and headers already sent to the client, nothing can be done.
+		ctx.Log().Errorf("Stream response body error: %s on %s", err, ctx.Req.Path)
+	}
+
+	if !sw.wroteHeader { // empty response body
+		e.writeStatus(ctx, false)
 	}
 
+	// 'OnAfterReply' server extension point
+	publishOnAfterReplyEvent(ctx)
+	return true
+}
+
+// writeContent method serves the file or seekable reader via
+// `http.ServeContent`, which handles byte ranges including multipart/byteranges,
+// `If-Modified-Since`, `If-None-Match`, `If-Range` and sets `Content-Length`.
+// ETag is generated from modification time and size, if it's not set by
+// application. Content is served without gzip, since ranges are on the
+// actual bytes. It returns false if file could not be opened, so error reply
+// can be written as usual.
+func (e *engine) writeContent(ctx *Context, binary *Binary) bool {
+	reply := ctx.Reply()
+	content, name, modTime, err := binary.content()
+	if err != nil {
+		ctx.Log().Error("Render response body error: ", err)
+		handleError(ctx, &HTTPError{Code: http.StatusInternalServerError, Err: err})
+		return false
+	}
+	defer ess.CloseQuietly(content)
+
+	if ess.IsStrEmpty(reply.Hdr.Get(headerETag)) && !modTime.IsZero() {
+		if size, err := content.Seek(0, io.SeekEnd); err == nil {
+			reply.Header(headerETag, fmt.Sprintf(`"%x-%x"`, modTime.Unix(), size))
+		}
+		if _, err = content.Seek(0, io.SeekStart); err != nil {
+			ctx.Log().Error("Render response body error: ", err)
+			handleError(ctx, &HTTPError{Code: http.StatusInternalServerError, Err: err})
+			return false
+		}
+	}
+
+	// 'OnPreReply' server extension point
+	publishOnPreReplyEvent(ctx)
+
+	// HTTP headers
+	e.writeHeaders(ctx)
+
+	// Set Cookies
+	e.setCookies(ctx)
+
+	// HTTP status and body
+	http.ServeContent(ctx.Res, ctx.Req.Raw, name, modTime, content)
+
+	// 'OnAfterReply' server extension point
+	publishOnAfterReplyEvent(ctx)
+	return true
+}
+
+// writeStatus method writes the HTTP headers, cookies and status on the wire.
+// Gzip writer is wrapped if response has body.
+func (e *engine) writeStatus(ctx *Context, hasBody bool) {
 	// Gzip
-	if !isNoGzipStatusCode(reply.Code) && reply.body.Len() != 0 {
+	if !isNoGzipStatusCode(ctx.Reply().Code) && hasBody {
 		e.wrapGzipWriter(ctx)
 		// TODO minify implementation for non-dev
 	}
@@ -291,13 +490,17 @@ func (e *engine) writeReply(ctx *Context) {
 	e.setCookies(ctx)
 
 	// HTTP status
-	ctx.Res.WriteHeader(reply.Code)
-
-	// Write response buffer on the wire
-	_, _ = reply.body.WriteTo(ctx.Res)
+	ctx.Res.WriteHeader(ctx.Reply().Code)
+}
 
-	// 'OnAfterReply' server extension point
-	publishOnAfterReplyEvent(ctx)
+// isStreamable method returns true if streaming is enabled and given render
+// is declared as streamable.
+func (e *engine) isStreamable(rdr Render) bool {
+	if !e.isStreamEnabled {
+		return false
+	}
+	streamer, ok := rdr.(Streamer)
+	return ok && streamer.IsStreamable()
 }
 
 // negotiateContentType method tries to identify if reply.ContType is empty.
@@ -316,14 +519,21 @@ func (e *engine) negotiateContentType(ctx *Context) {
 // wrapGzipWriter method writes respective header for gzip and wraps write into
 // gzip writer.
 func (e *engine) wrapGzipWriter(ctx *Context) {
-	if ctx.Req.IsGzipAccepted && e.isGzipEnabled && ctx.Reply().gzip {
+	if e.isGzipApplicable(ctx) {
 		ctx.Res.Header().Add(ahttp.HeaderVary, ahttp.HeaderAcceptEncoding)
 		ctx.Res.Header().Add(ahttp.HeaderContentEncoding, gzipContentEncoding)
 		ctx.Res.Header().Del(ahttp.HeaderContentLength)
 		ctx.Res = ahttp.GetGzipResponseWriter(ctx.Res)
+		metricsGzip()
 	}
 }
 
+// isGzipApplicable method returns true if reply body is gzipped as per
+// request `Accept-Encoding`, aah.conf `render.gzip.enable` and reply.
+func (e *engine) isGzipApplicable(ctx *Context) bool {
+	return ctx.Req.IsGzipAccepted && e.isGzipEnabled && ctx.Reply().gzip
+}
+
 // writeHeaders method writes the headers on the wire.
 func (e *engine) writeHeaders(ctx *Context) {
 	for k, v := range ctx.Reply().Hdr {
@@ -355,23 +565,26 @@ func (e *engine) setCookies(ctx *Context) {
 
 	if AppSessionManager().IsStateful() && ctx.session != nil {
 		if err := AppSessionManager().SaveSession(ctx.Res, ctx.session); err != nil {
-			log.Error(err)
+			ctx.Log().Error(err)
 		}
 	}
 }
 
 // getContext method gets context instance from the pool
 func (e *engine) getContext() *Context {
+	metricsPoolGet(poolContext)
 	return e.ctxPool.Get().(*Context)
 }
 
 // getRequest method gets request instance from the pool
 func (e *engine) getRequest() *ahttp.Request {
+	metricsPoolGet(poolRequest)
 	return e.reqPool.Get().(*ahttp.Request)
 }
 
 // getReply method gets reply instance from the pool
 func (e *engine) getReply() *Reply {
+	metricsPoolGet(poolReply)
 	return e.replyPool.Get().(*Reply)
 }
 
@@ -388,6 +601,7 @@ func (e *engine) putContext(ctx *Context) {
 
 	// clear and put `ahttp.Request` into pool
 	if ctx.Req != nil {
+		cleanupMultipartForm(ctx)
 		ctx.Req.Reset()
 		e.reqPool.Put(ctx.Req)
 	}
@@ -406,6 +620,7 @@ func (e *engine) putContext(ctx *Context) {
 
 // getBuffer method gets buffer from pool
 func (e *engine) getBuffer() *bytes.Buffer {
+	metricsPoolGet(poolBuffer)
 	return e.bufPool.Get().(*bytes.Buffer)
 }
 
@@ -428,33 +643,55 @@ func newEngine(cfg *config.Config) *engine {
 		logAsFatal(fmt.Errorf("'render.gzip.level' is not a valid level value: %v", ahttp.GzipLevel))
 	}
 
+	contentTypes, _ := cfg.StringList("request.content_types")
+	appRequestIDHeader = cfg.StringDefault("request.id.header", ahttp.HeaderXRequestID)
+
 	return &engine{
 		isRequestIDEnabled: cfg.BoolDefault("request.id.enable", true),
 		requestIDHeader:    cfg.StringDefault("request.id.header", ahttp.HeaderXRequestID),
 		isGzipEnabled:      cfg.BoolDefault("render.gzip.enable", true),
+		isStreamEnabled:    cfg.BoolDefault("render.stream.enable", true),
+		isETagEnabled:      cfg.BoolDefault("render.etag.enable", false),
+		isWeakETag:         cfg.BoolDefault("render.etag.weak", false),
+		contentTypes:       contentTypes,
 		ctxPool: pool.NewPool(
 			cfg.IntDefault("runtime.pooling.global", defaultGlobalPoolSize),
 			func() interface{} {
+				metricsPoolMiss(poolContext)
 				return &Context{}
 			},
 		),
 		reqPool: pool.NewPool(
 			cfg.IntDefault("runtime.pooling.global", defaultGlobalPoolSize),
 			func() interface{} {
+				metricsPoolMiss(poolRequest)
 				return &ahttp.Request{}
 			},
 		),
 		replyPool: pool.NewPool(
 			cfg.IntDefault("runtime.pooling.global", defaultGlobalPoolSize),
 			func() interface{} {
+				metricsPoolMiss(poolReply)
 				return NewReply()
 			},
 		),
 		bufPool: pool.NewPool(
 			cfg.IntDefault("runtime.pooling.buffer", defaultBufPoolSize),
 			func() interface{} {
+				metricsPoolMiss(poolBuffer)
 				return &bytes.Buffer{}
 			},
 		),
 	}
 }
+
+// Write method writes the HTTP headers, cookies and status on first write
+// then given bytes on the wire. Chunked transfer encoding is applied by
+// Go HTTP server since Content-Length is unknown.
+func (sw *streamWriter) Write(b []byte) (int, error) {
+	if !sw.wroteHeader {
+		sw.wroteHeader = true
+		sw.e.writeStatus(sw.ctx, true)
+	}
+	return sw.ctx.Res.Write(b)
+}
@@ -5,17 +5,21 @@
 package aah
 
 import (
+	"bytes"
 	"compress/gzip"
+	"fmt"
 	"io/ioutil"
 	"net/http"
 	"net/http/httptest"
 	"os"
 	"path/filepath"
+	"strconv"
 	"strings"
 	"testing"
 
 	"aahframework.org/ahttp.v0"
 	"aahframework.org/config.v0"
+	"aahframework.org/essentials.v0"
 	"aahframework.org/log.v0"
 	"aahframework.org/test.v0/assert"
 )
@@ -243,3 +247,116 @@ func TestEngineGzipHeaders(t *testing.T) {
 	assert.Equal(t, "gzip", ctx.Res.Header().Get(ahttp.HeaderContentEncoding))
 	assert.Equal(t, "Accept-Encoding", ctx.Res.Header().Get(ahttp.HeaderVary))
 }
+
+func TestEngineWriteStream(t *testing.T) {
+	appConfig, _ = config.ParseString("")
+	err := initSecurity(filepath.Join(getTestdataPath(), appConfigDir()), appConfig)
+	assert.FailNowOnError(t, err, "")
+	e := newEngine(appConfig)
+	assert.True(t, e.isStreamEnabled)
+
+	newCtx := func(w http.ResponseWriter) *Context {
+		req := httptest.NewRequest("GET", "http://localhost:8080/exports/report", nil)
+		req.Header.Set(ahttp.HeaderAccept, ahttp.ContentTypePlainText.Raw())
+		return e.prepareContext(w, req)
+	}
+
+	// streamed on the wire, not buffered
+	w1 := httptest.NewRecorder()
+	ctx1 := newCtx(w1)
+	ctx1.Reply().ContentType(ahttp.ContentTypeOctetStream.Raw()).
+		Readfrom(ioutil.NopCloser(strings.NewReader("large export content")))
+	assert.True(t, e.isStreamable(ctx1.Reply().Rdr))
+
+	e.writeReply(ctx1)
+	assert.Nil(t, ctx1.Reply().body)
+	assert.Equal(t, http.StatusOK, w1.Code)
+	assert.Equal(t, ahttp.ContentTypeOctetStream.Raw(), w1.Header().Get(ahttp.HeaderContentType))
+	assert.Equal(t, "large export content", w1.Body.String())
+
+	// render error before write, falls back to error reply
+	w2 := httptest.NewRecorder()
+	ctx2 := newCtx(w2)
+	ctx2.Reply().File(filepath.Join(getTestdataPath(), "file-not-exists.txt"))
+
+	e.writeReply(ctx2)
+	assert.Equal(t, http.StatusInternalServerError, w2.Code)
+	assert.Equal(t, "500 Internal Server Error", w2.Body.String())
+
+	// streaming disabled
+	e.isStreamEnabled = false
+	assert.False(t, e.isStreamable(&Binary{Path: "report.csv"}))
+}
+
+func TestEngineWriteContent(t *testing.T) {
+	appConfig, _ = config.ParseString("")
+	err := initSecurity(filepath.Join(getTestdataPath(), appConfigDir()), appConfig)
+	assert.FailNowOnError(t, err, "")
+	e := newEngine(appConfig)
+
+	file := getRenderFilepath("file1.txt")
+	fi, _ := os.Stat(file)
+	etag := fmt.Sprintf(`"%x-%x"`, fi.ModTime().Unix(), fi.Size())
+
+	serve := func(rdr *Binary, hdrs map[string]string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest("GET", "http://localhost:8080/downloads/file1.txt", nil)
+		for k, v := range hdrs {
+			req.Header.Set(k, v)
+		}
+		w := httptest.NewRecorder()
+		ctx := e.prepareContext(w, req)
+		ctx.Reply().Rdr = rdr
+		e.writeReply(ctx)
+		return w
+	}
+
+	// full content
+	w := serve(&Binary{Path: file}, nil)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, strconv.FormatInt(fi.Size(), 10), w.Header().Get(ahttp.HeaderContentLength))
+	assert.Equal(t, etag, w.Header().Get(headerETag))
+	assert.Equal(t, fi.ModTime().UTC().Format(http.TimeFormat), w.Header().Get("Last-Modified"))
+	assert.True(t, strings.HasPrefix(w.Header().Get(ahttp.HeaderContentType), "text/plain"))
+	assert.Equal(t, "bytes", w.Header().Get("Accept-Ranges"))
+
+	// byte range
+	w = serve(&Binary{Path: file}, map[string]string{"Range": "bytes=1-4"})
+	assert.Equal(t, http.StatusPartialContent, w.Code)
+	assert.Equal(t, "Each", w.Body.String())
+	assert.Equal(t, fmt.Sprintf("bytes 1-4/%d", fi.Size()), w.Header().Get("Content-Range"))
+
+	// multiple byte ranges
+	w = serve(&Binary{Path: file}, map[string]string{"Range": "bytes=1-4,6-13"})
+	assert.Equal(t, http.StatusPartialContent, w.Code)
+	assert.True(t, strings.HasPrefix(w.Header().Get(ahttp.HeaderContentType), "multipart/byteranges; boundary="))
+
+	// not satisfiable
+	w = serve(&Binary{Path: file}, map[string]string{"Range": "bytes=5000-"})
+	assert.Equal(t, http.StatusRequestedRangeNotSatisfiable, w.Code)
+
+	// If-None-Match
+	w = serve(&Binary{Path: file}, map[string]string{"If-None-Match": etag})
+	assert.Equal(t, http.StatusNotModified, w.Code)
+	assert.True(t, ess.IsStrEmpty(w.Body.String()))
+
+	// If-Modified-Since
+	w = serve(&Binary{Path: file}, map[string]string{"If-Modified-Since": fi.ModTime().UTC().Format(http.TimeFormat)})
+	assert.Equal(t, http.StatusNotModified, w.Code)
+
+	// If-Range with stale ETag, full content is served
+	w = serve(&Binary{Path: file}, map[string]string{"Range": "bytes=1-4", "If-Range": `"stale"`})
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, fi.Size(), int64(w.Body.Len()))
+
+	// seekable reader
+	w = serve(&Binary{Reader: bytes.NewReader([]byte("aah web framework")), Seekable: true}, map[string]string{"Range": "bytes=4-6"})
+	assert.Equal(t, http.StatusPartialContent, w.Code)
+	assert.Equal(t, "web", w.Body.String())
+	assert.True(t, ess.IsStrEmpty(w.Header().Get(headerETag)))
+
+	// in-memory bytes, range is ignored
+	w = serve(&Binary{Reader: bytes.NewReader([]byte("aah web framework"))}, map[string]string{"Range": "bytes=4-6"})
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "aah web framework", w.Body.String())
+	assert.True(t, ess.IsStrEmpty(w.Header().Get("Accept-Ranges")))
+}
@@ -0,0 +1,246 @@
+// Copyright (c) Jeevanandam M. (https://github.com/jeevatkm)
+// go-aah/aah source code and usage is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package aah
+
+import (
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"html/template"
+	"net/http"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"aahframework.org/ahttp.v0"
+	"aahframework.org/essentials.v0"
+	"aahframework.org/log.v0"
+	"aahframework.org/view.v0"
+)
+
+const (
+	keyError               = "Error"
+	errorFormatProblem     = "problem"
+	problemTypeBlank       = "about:blank"
+	contentTypeProblemJSON = "application/problem+json; charset=utf-8"
+)
+
+var (
+	errorHandler   ErrorHandler
+	errorTemplates = make(map[int]*template.Template)
+
+	defaultErrorTemplate = template.Must(template.New("error").Parse(`
+		<strong>{{ .Error.Code }} {{ .Error.Message }}</strong>
+		{{ with .ValidationErrors }}<ul>{{ range . }}<li>{{ .Message }}</li>{{ end }}</ul>{{ end }}
+	`))
+)
+
+type (
+	// HTTPError holds the details of HTTP error reply such as status code,
+	// message, data and the actual cause. Framework replies every error via
+	// error handler, refer `SetErrorHandler`.
+	HTTPError struct {
+		XMLName xml.Name    `json:"-" xml:"error"`
+		Code    int         `json:"code" xml:"code"`
+		Message string      `json:"message" xml:"message"`
+		Data    interface{} `json:"data,omitempty" xml:"data,omitempty"`
+		Err     error       `json:"-" xml:"-"`
+	}
+
+	// ErrorHandler func type is aah framework error handler signature. It
+	// returns true if error is handled otherwise false, then framework
+	// handles the error with default error handler.
+	ErrorHandler func(ctx *Context, err *HTTPError) bool
+)
+
+//‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾
+// Global methods
+//___________________________________
+
+// NewHTTPError method returns the HTTP error for given status code and message.
+// Message defaults to HTTP status text if empty.
+func NewHTTPError(code int, message string) *HTTPError {
+	if ess.IsStrEmpty(message) {
+		message = http.StatusText(code)
+	}
+	return &HTTPError{Code: code, Message: message}
+}
+
+// SetErrorHandler method sets the given centralized error handler, every error
+// reply goes through it; such as route not found, method not allowed, bad
+// request, panic recovery, render failures, static file errors, etc.
+//
+// Default error handler renders the error based on negotiated content type
+// JSON, XML, HTML and Text. JSON error is rendered as RFC 7807 problem details
+// if `render.error.format = "problem"` is set in aah.conf. HTML error is rendered with the template
+// `views/errors/<code>.html` if exists otherwise framework default template,
+// only if application has views otherwise Text.
+// Route not found error has no controller and action, so the HTML template
+// is resolved from `views/pages/` directory with given filename.
+//		For Example:
+//
+//		aah.SetErrorHandler(func(ctx *aah.Context, err *aah.HTTPError) bool {
+//			if err.Code == http.StatusNotFound {
+//				// renders `views/pages/notfound.html`
+//				ctx.Reply().NotFound().HTMLf("notfound.html", nil)
+//				return true
+//			}
+//			return false
+//		})
+func SetErrorHandler(handler ErrorHandler) {
+	errorHandler = handler
+}
+
+//‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾
+// HTTPError methods
+//___________________________________
+
+// Error method is implementation of error interface.
+func (e *HTTPError) Error() string {
+	if e.Err == nil {
+		return fmt.Sprintf("%d %s", e.Code, e.Message)
+	}
+	return fmt.Sprintf("%d %s: %s", e.Code, e.Message, e.Err)
+}
+
+//‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾
+// Unexported methods
+//___________________________________
+
+// handleError method handles the error via user defined error handler
+// if set otherwise default error handler.
+func handleError(ctx *Context, err *HTTPError) {
+	if ess.IsStrEmpty(err.Message) {
+		err.Message = http.StatusText(err.Code)
+	}
+
+	if errorHandler != nil && errorHandler(ctx, err) {
+		return
+	}
+
+	defaultErrorHandler(ctx, err)
+}
+
+// defaultErrorHandler method replies the error based on negotiated
+// content type.
+func defaultErrorHandler(ctx *Context, err *HTTPError) {
+	reply := ctx.Reply().Status(err.Code)
+	contentType := errorContentType(ctx)
+	if isJSONContentType(contentType) {
+		if AppConfig().StringDefault("render.error.format", "") == errorFormatProblem {
+			reply.Problem(newErrorProblem(ctx, err))
+		} else {
+			reply.JSONWithOptions(err, unprefixedJSONOptions())
+		}
+	} else if isXMLContentType(contentType) {
+		reply.XML(err)
+	} else if ahttp.ContentTypeHTML.IsEqual(contentType) && appViewEngine != nil {
+		viewArgs := Data{}
+		for k, v := range ctx.ViewArgs() {
+			viewArgs[k] = v
+		}
+		viewArgs[keyError] = err
+
+		reply.ContentType(ahttp.ContentTypeHTML.Raw())
+		reply.Rdr = &HTML{Template: findErrorTemplate(err.Code), ViewArgs: viewArgs}
+	} else {
+		reply.Text(errorText(err))
+	}
+}
+
+// newErrorProblem method returns the RFC 7807 problem details for the
+// given HTTP error, error data is added as extension member `errors`.
+func newErrorProblem(ctx *Context, err *HTTPError) *Problem {
+	problem := &Problem{
+		Type:     problemTypeBlank,
+		Title:    http.StatusText(err.Code),
+		Status:   err.Code,
+		Instance: ctx.Req.Path,
+	}
+
+	if err.Message != problem.Title {
+		problem.Detail = err.Message
+	}
+
+	if err.Data != nil {
+		problem.Extensions = Data{"errors": err.Data}
+	}
+
+	return problem
+}
+
+// errorContentType method returns the reply Content-Type mime if set otherwise
+// request `Accept` header otherwise `render.default` from aah.conf.
+func errorContentType(ctx *Context) string {
+	if ctx.Reply().IsContentTypeSet() {
+		return mimeOf(ctx.Reply().ContType)
+	}
+
+	if acceptMime := ctx.Req.AcceptContentType.Mime; !ess.IsStrEmpty(acceptMime) && acceptMime != "*/*" {
+		return acceptMime
+	}
+
+	if ct := defaultContentType(); ct != nil {
+		return ct.Mime
+	}
+	return ""
+}
+
+// errorText method returns the plain text of HTTP error.
+func errorText(err *HTTPError) string {
+	buf := &bytes.Buffer{}
+	fmt.Fprintf(buf, "%d %s", err.Code, err.Message)
+	switch data := err.Data.(type) {
+	case []*ValidationError:
+		for _, ve := range data {
+			fmt.Fprintf(buf, "\n%s", ve.Message)
+		}
+	case *BindError:
+		fmt.Fprintf(buf, "\n%s", data)
+	}
+	return buf.String()
+}
+
+// findErrorTemplate method returns the error template for the given status code
+// from `views/errors` otherwise framework default error template.
+func findErrorTemplate(code int) *template.Template {
+	if tmpl, found := errorTemplates[code]; found {
+		return tmpl
+	}
+	return defaultErrorTemplate
+}
+
+// initErrorTemplates method parses the error templates `<code>.html`
+// from given directory.
+func initErrorTemplates(errorsDir string) error {
+	errorTemplates = make(map[int]*template.Template)
+	if !ess.IsFileExists(errorsDir) {
+		return nil
+	}
+
+	files, err := filepath.Glob(filepath.Join(errorsDir, "*"+appViewExt))
+	if err != nil {
+		return err
+	}
+
+	for _, file := range files {
+		name := filepath.Base(file)
+		code, err := strconv.Atoi(strings.TrimSuffix(name, appViewExt))
+		if err != nil {
+			log.Warnf("error template name is not a HTTP status code, skip it: %s", file)
+			continue
+		}
+
+		tmpl, err := template.New(name).Funcs(view.TemplateFuncMap).ParseFiles(file)
+		if err != nil {
+			return fmt.Errorf("error template: %s", err)
+		}
+
+		log.Tracef("Error template loaded: %s", file)
+		errorTemplates[code] = tmpl
+	}
+
+	return nil
+}
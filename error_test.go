@@ -0,0 +1,188 @@
+// Copyright (c) Jeevanandam M. (https://github.com/jeevatkm)
+// go-aah/aah source code and usage is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package aah
+
+import (
+	"bytes"
+	"errors"
+	"html/template"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"aahframework.org/ahttp.v0"
+	"aahframework.org/config.v0"
+	"aahframework.org/test.v0/assert"
+	"aahframework.org/view.v0"
+)
+
+func TestErrorHTTPError(t *testing.T) {
+	err := NewHTTPError(http.StatusNotFound, "")
+	assert.Equal(t, "Not Found", err.Message)
+	assert.Equal(t, "404 Not Found", err.Error())
+
+	err = NewHTTPError(http.StatusForbidden, "Directory listing not allowed")
+	assert.Equal(t, "403 Directory listing not allowed", err.Error())
+
+	err = &HTTPError{Code: http.StatusInternalServerError, Message: "Internal Server Error", Err: errors.New("runtime error")}
+	assert.Equal(t, "500 Internal Server Error: runtime error", err.Error())
+}
+
+func TestErrorDefaultHandler(t *testing.T) {
+	appConfig, _ = config.ParseString("")
+
+	newCtx := func(accept string) *Context {
+		req := httptest.NewRequest("GET", "http://localhost:8080/users/1001", nil)
+		req.Header.Set(ahttp.HeaderAccept, accept)
+		return &Context{
+			Req:      ahttp.ParseRequest(req, &ahttp.Request{}),
+			reply:    NewReply(),
+			viewArgs: make(map[string]interface{}),
+		}
+	}
+
+	render := func(ctx *Context) string {
+		buf := &bytes.Buffer{}
+		assert.Nil(t, ctx.Reply().Rdr.Render(buf))
+		return buf.String()
+	}
+
+	// JSON
+	ctx := newCtx(ahttp.ContentTypeJSON.Raw())
+	handleError(ctx, &HTTPError{Code: http.StatusNotFound, Err: errors.New("user not found")})
+	assert.Equal(t, http.StatusNotFound, ctx.Reply().Code)
+	assert.Equal(t, ahttp.ContentTypeJSON.Raw(), ctx.Reply().ContType)
+	assert.Equal(t, `{"code":404,"message":"Not Found"}`, render(ctx))
+
+	// XML
+	ctx = newCtx(ahttp.ContentTypeXML.Raw())
+	handleError(ctx, &HTTPError{Code: http.StatusMethodNotAllowed})
+	assert.Equal(t, http.StatusMethodNotAllowed, ctx.Reply().Code)
+	assert.Equal(t, "<error><code>405</code><message>Method Not Allowed</message></error>", render(ctx))
+
+	// HTML, application without views
+	ctx = newCtx(ahttp.ContentTypeHTML.Raw())
+	handleError(ctx, &HTTPError{Code: http.StatusNotFound})
+	assert.Equal(t, "404 Not Found", render(ctx))
+
+	// HTML
+	appViewEngine = &view.GoViewEngine{}
+	defer func() { appViewEngine = nil }()
+	ctx = newCtx(ahttp.ContentTypeHTML.Raw())
+	handleError(ctx, &HTTPError{Code: http.StatusBadRequest, Data: []*ValidationError{{Message: "Name is required"}}})
+	ctx.AddViewArg(keyValidationErrors, ctx.Reply().Rdr.(*HTML).ViewArgs[keyError].(*HTTPError).Data)
+	assert.Equal(t, ahttp.ContentTypeHTML.Raw(), ctx.Reply().ContType)
+	assert.Equal(t, defaultErrorTemplate, ctx.Reply().Rdr.(*HTML).Template)
+	assert.True(t, strings.Contains(render(ctx), "<strong>400 Bad Request</strong>"))
+
+	// Text
+	ctx = newCtx(ahttp.ContentTypePlainText.Raw())
+	handleError(ctx, &HTTPError{Code: http.StatusBadRequest, Data: []*ValidationError{{Message: "Name is required"}}})
+	assert.Equal(t, "400 Bad Request\nName is required", render(ctx))
+
+	// Reply content type takes precedence
+	ctx = newCtx(ahttp.ContentTypeHTML.Raw())
+	ctx.Reply().ContentType(ahttp.ContentTypeJSON.Raw())
+	handleError(ctx, &HTTPError{Code: http.StatusInternalServerError})
+	assert.Equal(t, `{"code":500,"message":"Internal Server Error"}`, render(ctx))
+}
+
+func TestErrorProblemFormat(t *testing.T) {
+	appConfig, _ = config.ParseString("")
+	appConfig.SetString("render.error.format", "problem")
+	defer func() { appConfig = nil }()
+
+	newCtx := func(accept string) *Context {
+		req := httptest.NewRequest("POST", "http://localhost:8080/users", nil)
+		req.Header.Set(ahttp.HeaderAccept, accept)
+		return &Context{Req: ahttp.ParseRequest(req, &ahttp.Request{}), reply: NewReply()}
+	}
+
+	ctx := newCtx("application/problem+json")
+	handleError(ctx, &HTTPError{Code: http.StatusMethodNotAllowed})
+	assert.Equal(t, http.StatusMethodNotAllowed, ctx.Reply().Code)
+	assert.Equal(t, contentTypeProblemJSON, ctx.Reply().ContType)
+
+	buf := &bytes.Buffer{}
+	assert.Nil(t, ctx.Reply().Rdr.Render(buf))
+	assert.Equal(t, `{"instance":"/users","status":405,"title":"Method Not Allowed","type":"about:blank"}`, buf.String())
+
+	ctx = newCtx(ahttp.ContentTypeJSON.Raw())
+	handleError(ctx, &HTTPError{Code: http.StatusBadRequest, Message: "Invalid payload",
+		Data: []*ValidationError{{Field: "Name", Tag: "required", Message: "Name is required"}}})
+	problem := ctx.Reply().Rdr.(*Problem)
+	assert.Equal(t, "Bad Request", problem.Title)
+	assert.Equal(t, "Invalid payload", problem.Detail)
+	assert.NotNil(t, problem.Extensions["errors"])
+
+	// XML is not affected
+	ctx = newCtx(ahttp.ContentTypeXML.Raw())
+	handleError(ctx, &HTTPError{Code: http.StatusNotFound})
+	_, ok := ctx.Reply().Rdr.(*XML)
+	assert.True(t, ok)
+}
+
+func TestErrorSetErrorHandler(t *testing.T) {
+	appConfig, _ = config.ParseString("")
+	defer SetErrorHandler(nil)
+
+	SetErrorHandler(func(ctx *Context, err *HTTPError) bool {
+		if err.Code == http.StatusNotFound {
+			ctx.Reply().NotFound().Text("custom: %s", err.Message)
+			return true
+		}
+		return false
+	})
+
+	req := httptest.NewRequest("GET", "http://localhost:8080/users/1001", nil)
+	req.Header.Set(ahttp.HeaderAccept, ahttp.ContentTypePlainText.Raw())
+	ctx := &Context{Req: ahttp.ParseRequest(req, &ahttp.Request{}), reply: NewReply()}
+
+	handleError(ctx, &HTTPError{Code: http.StatusNotFound})
+	assert.Equal(t, http.StatusNotFound, ctx.Reply().Code)
+	assert.Equal(t, "custom: %s", ctx.Reply().Rdr.(*Text).Format)
+
+	// not handled by custom handler
+	ctx.reply = NewReply()
+	handleError(ctx, &HTTPError{Code: http.StatusUnsupportedMediaType})
+	assert.Equal(t, http.StatusUnsupportedMediaType, ctx.Reply().Code)
+	assert.Equal(t, "415 Unsupported Media Type", ctx.Reply().Rdr.(*Text).Format)
+}
+
+func TestErrorTemplates(t *testing.T) {
+	appViewExt = ".html"
+	defer func() {
+		appViewExt = ""
+		errorTemplates = make(map[int]*template.Template)
+	}()
+
+	err := initErrorTemplates(filepath.Join(getTestdataPath(), "views", "errors"))
+	assert.Nil(t, err)
+	assert.NotNil(t, errorTemplates[http.StatusNotFound])
+
+	assert.Equal(t, errorTemplates[http.StatusNotFound], findErrorTemplate(http.StatusNotFound))
+	assert.Equal(t, defaultErrorTemplate, findErrorTemplate(http.StatusInternalServerError))
+
+	// framework ViewArgs are populated for resolved template
+	appConfig, _ = config.ParseString("")
+	e := newEngine(appConfig)
+	ctx := e.prepareContext(httptest.NewRecorder(), httptest.NewRequest("GET", "http://localhost:8080/users/1001", nil))
+	htmlRdr := &HTML{
+		Template: findErrorTemplate(http.StatusNotFound),
+		ViewArgs: Data{keyError: NewHTTPError(http.StatusNotFound, "")},
+	}
+	ctx.Reply().ContentType(ahttp.ContentTypeHTML.Raw())
+	ctx.Reply().Rdr = htmlRdr
+	e.resolveView(ctx)
+	assert.Equal(t, "", htmlRdr.Layout)
+	assert.Equal(t, "/users/1001", htmlRdr.ViewArgs["RequestPath"])
+
+	buf := &bytes.Buffer{}
+	assert.Nil(t, htmlRdr.Render(buf))
+	assert.True(t, strings.Contains(buf.String(), "<h1>404 Not Found</h1>"))
+	assert.True(t, strings.Contains(buf.String(), "is not found: /users/1001"))
+}
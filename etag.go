@@ -0,0 +1,76 @@
+// Copyright (c) Jeevanandam M. (https://github.com/jeevatkm)
+// go-aah/aah source code and usage is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package aah
+
+import (
+	"crypto/sha1"
+	"encoding/hex"
+	"net/http"
+	"strings"
+
+	"aahframework.org/ahttp.v0"
+	"aahframework.org/essentials.v0"
+)
+
+const (
+	headerIfNoneMatch = "If-None-Match"
+	weakETagPrefix    = "W/"
+)
+
+//‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾
+// Unexported methods
+//___________________________________
+
+// writeETag method sets the ETag of rendered reply body if it's enabled via
+// aah.conf `render.etag.enable` or per route `etag.enable` in routes.conf.
+// Reply becomes `304 Not Modified` without body if ETag matches
+// the request header `If-None-Match`. ETag set by application is not
+// overridden. ETag is weak if reply is gzipped.
+func (e *engine) writeETag(ctx *Context) {
+	reply := ctx.Reply()
+	if reply.Code != http.StatusOK || reply.body.Len() == 0 ||
+		!(ctx.Req.Method == ahttp.MethodGet || ctx.Req.Method == ahttp.MethodHead) ||
+		!ess.IsStrEmpty(reply.Hdr.Get(headerETag)) ||
+		!routeBoolDefault(ctx, "etag.enable", e.isETagEnabled) {
+		return
+	}
+
+	// gzipped body differs from the hashed bytes, so ETag is weak
+	weak := routeBoolDefault(ctx, "etag.weak", e.isWeakETag) || e.isGzipApplicable(ctx)
+	etag := generateETag(reply.body.Bytes(), weak)
+	reply.Header(headerETag, etag)
+
+	if isETagMatch(ctx.Req.Header.Get(headerIfNoneMatch), etag) {
+		reply.Status(http.StatusNotModified)
+		reply.body.Reset()
+	}
+}
+
+// generateETag method returns the strong or weak ETag for the given bytes.
+func generateETag(b []byte, weak bool) string {
+	sum := sha1.Sum(b)
+	etag := `"` + hex.EncodeToString(sum[:]) + `"`
+	if weak {
+		return weakETagPrefix + etag
+	}
+	return etag
+}
+
+// isETagMatch method returns true if given ETag matches any of the
+// `If-None-Match` values using weak comparison as per RFC 7232, 3.2.
+func isETagMatch(ifNoneMatch, etag string) bool {
+	if ess.IsStrEmpty(ifNoneMatch) {
+		return false
+	}
+
+	etag = strings.TrimPrefix(etag, weakETagPrefix)
+	for _, v := range strings.Split(ifNoneMatch, ",") {
+		v = strings.TrimSpace(v)
+		if v == "*" || strings.TrimPrefix(v, weakETagPrefix) == etag {
+			return true
+		}
+	}
+	return false
+}
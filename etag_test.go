@@ -0,0 +1,107 @@
+// Copyright (c) Jeevanandam M. (https://github.com/jeevatkm)
+// go-aah/aah source code and usage is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package aah
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+
+	"aahframework.org/ahttp.v0"
+	"aahframework.org/config.v0"
+	"aahframework.org/essentials.v0"
+	"aahframework.org/router.v0"
+	"aahframework.org/test.v0/assert"
+)
+
+func TestETagGenerate(t *testing.T) {
+	etag := generateETag([]byte(`{"status":"ok"}`), false)
+	assert.Equal(t, 42, len(etag))
+	assert.Equal(t, etag, generateETag([]byte(`{"status":"ok"}`), false))
+	assert.NotEqual(t, etag, generateETag([]byte(`{"status":"down"}`), false))
+	assert.Equal(t, "W/"+etag, generateETag([]byte(`{"status":"ok"}`), true))
+
+	assert.True(t, isETagMatch(etag, etag))
+	assert.True(t, isETagMatch(`"abc", `+etag, etag))
+	assert.True(t, isETagMatch("W/"+etag, etag))
+	assert.True(t, isETagMatch(etag, "W/"+etag))
+	assert.True(t, isETagMatch("*", etag))
+	assert.False(t, isETagMatch("", etag))
+	assert.False(t, isETagMatch(`"abc"`, etag))
+}
+
+func TestETagWriteReply(t *testing.T) {
+	appConfig, _ = config.ParseString("")
+	appConfig.SetBool("render.etag.enable", true)
+	err := initSecurity(filepath.Join(getTestdataPath(), appConfigDir()), appConfig)
+	assert.FailNowOnError(t, err, "")
+	e := newEngine(appConfig)
+	assert.True(t, e.isETagEnabled)
+	assert.False(t, e.isWeakETag)
+
+	etag := generateETag([]byte(`{"status":"ok"}`), false)
+	acceptEncoding := ""
+	reply := func(method, ifNoneMatch string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, "http://localhost:8080/status", nil)
+		req.Header.Set(ahttp.HeaderAccept, ahttp.ContentTypeJSON.Raw())
+		req.Header.Set(ahttp.HeaderAcceptEncoding, acceptEncoding)
+		if !ess.IsStrEmpty(ifNoneMatch) {
+			req.Header.Set(headerIfNoneMatch, ifNoneMatch)
+		}
+		w := httptest.NewRecorder()
+		ctx := e.prepareContext(w, req)
+		ctx.domain = &router.Domain{Host: "localhost"}
+		ctx.route = &router.Route{Name: "status"}
+		ctx.Reply().JSON(Data{"status": "ok"})
+		e.writeReply(ctx)
+		return w
+	}
+
+	w := reply(ahttp.MethodGet, "")
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, etag, w.Header().Get(headerETag))
+	assert.Equal(t, `{"status":"ok"}`, w.Body.String())
+
+	w = reply(ahttp.MethodGet, etag)
+	assert.Equal(t, http.StatusNotModified, w.Code)
+	assert.Equal(t, etag, w.Header().Get(headerETag))
+	assert.True(t, ess.IsStrEmpty(w.Body.String()))
+	assert.True(t, ess.IsStrEmpty(w.Header().Get(ahttp.HeaderContentEncoding)))
+
+	// gzipped reply, weak ETag
+	acceptEncoding = "gzip, deflate"
+	w = reply(ahttp.MethodGet, "")
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, gzipContentEncoding, w.Header().Get(ahttp.HeaderContentEncoding))
+	assert.Equal(t, "W/"+etag, w.Header().Get(headerETag))
+
+	w = reply(ahttp.MethodGet, "W/"+etag)
+	assert.Equal(t, http.StatusNotModified, w.Code)
+	acceptEncoding = ""
+
+	// not applicable for POST
+	w = reply(ahttp.MethodPost, etag)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.True(t, ess.IsStrEmpty(w.Header().Get(headerETag)))
+
+	// per route, weak ETag
+	appRoutesConfig, _ = config.ParseString("")
+	appRoutesConfig.SetBool("domains.localhost.routes.status.etag.weak", true)
+	appRouteConfigPaths = map[string]string{
+		routeConfigKey("localhost", "status"): "domains.localhost.routes.status",
+	}
+	defer func() { appRoutesConfig, appRouteConfigPaths = nil, nil }()
+
+	w = reply(ahttp.MethodGet, etag)
+	assert.Equal(t, http.StatusNotModified, w.Code)
+	assert.Equal(t, "W/"+etag, w.Header().Get(headerETag))
+
+	// per route, disabled
+	appRoutesConfig.SetBool("domains.localhost.routes.status.etag.enable", false)
+	w = reply(ahttp.MethodGet, etag)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.True(t, ess.IsStrEmpty(w.Header().Get(headerETag)))
+}
@@ -39,6 +39,11 @@ const (
 	//   2) `Reply().Redirect(...)` is called.
 	// Refer `aah.Reply.Done()` godoc for more info.
 	EventOnAfterReply = "OnAfterReply"
+
+	// EventOnSlowRequest event is fired asynchronously when request handling
+	// exceeds the aah.conf `server.slow_request.threshold`, event data is
+	// `*aah.SlowRequest`. It's enabled via `server.slow_request.publish_event`.
+	EventOnSlowRequest = "OnSlowRequest"
 )
 
 var (
@@ -341,6 +346,9 @@ func publishOnAfterReplyEvent(ctx *Context) {
 	if onAfterReplyFunc != nil {
 		onAfterReplyFunc(&Event{Name: EventOnAfterReply, Data: ctx})
 	}
+
+	// Request access log
+	sendToAccessLog(ctx)
 }
 
 // funcEqual method to compare to function callback interface data. In effect
@@ -0,0 +1,295 @@
+// Copyright (c) Jeevanandam M. (https://github.com/jeevatkm)
+// go-aah/aah source code and usage is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package aah
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"aahframework.org/ahttp.v0"
+	"aahframework.org/config.v0"
+	"aahframework.org/essentials.v0"
+	"aahframework.org/log.v0"
+)
+
+const (
+	// HealthStatusUp is health report status when all the checks passed.
+	HealthStatusUp = "up"
+
+	// HealthStatusDown is health report status when any of the check failed,
+	// timed out or aah server is not ready.
+	HealthStatusDown = "down"
+
+	defaultHealthLivenessPath  = "/healthz"
+	defaultHealthReadinessPath = "/readyz"
+)
+
+var (
+	appHealth       *health
+	appHealthServer *http.Server
+	appReady        int32
+
+	appHealthChecks   []*healthCheck
+	appHealthChecksMu = &sync.Mutex{}
+)
+
+type (
+	// HealthCheckFunc is application health check, it returns error if the
+	// resource is unhealthy. Given context is cancelled when the check
+	// exceeds aah.conf `server.health.timeout`.
+	HealthCheckFunc func(ctx context.Context) error
+
+	// HealthReport is the JSON report of liveness and readiness endpoints.
+	HealthReport struct {
+		Status  string               `json:"status"`
+		Message string               `json:"message,omitempty"`
+		Checks  []*HealthCheckResult `json:"checks,omitempty"`
+	}
+
+	// HealthCheckResult is the result of single health check.
+	HealthCheckResult struct {
+		Name    string `json:"name"`
+		Status  string `json:"status"`
+		Latency string `json:"latency"`
+		Error   string `json:"error,omitempty"`
+	}
+
+	// health serves the liveness and readiness endpoints of aah server.
+	health struct {
+		livenessPath  string
+		readinessPath string
+		address       string
+		timeout       time.Duration
+	}
+
+	healthCheck struct {
+		name  string
+		check HealthCheckFunc
+	}
+)
+
+//‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾
+// Global methods
+//___________________________________
+
+// AddHealthCheck method registers the named health check, check results are
+// aggregated into JSON report of `server.health` readiness endpoint. Check
+// with same name is replaced.
+//		For Example:
+//
+//		func init() {
+//			aah.AddHealthCheck("database", func(ctx context.Context) error {
+//				return db.PingContext(ctx)
+//			})
+//		}
+func AddHealthCheck(name string, check HealthCheckFunc) {
+	if ess.IsStrEmpty(name) || check == nil {
+		log.Warn("Health check name and func are required")
+		return
+	}
+
+	appHealthChecksMu.Lock()
+	defer appHealthChecksMu.Unlock()
+	for _, hc := range appHealthChecks {
+		if hc.name == name {
+			hc.check = check
+			return
+		}
+	}
+	appHealthChecks = append(appHealthChecks, &healthCheck{name: name, check: check})
+}
+
+// AppIsReady method returns true if aah server is ready to serve the
+// requests, i.e. `OnStart` event is completed, server listener is up and
+// server is not shutting down.
+func AppIsReady() bool {
+	return atomic.LoadInt32(&appReady) == 1
+}
+
+//‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾
+// health methods
+//___________________________________
+
+// ServeHTTP method serves the liveness and readiness endpoints. Liveness
+// reports only the process is up, health checks are not run, so failing
+// dependency doesn't restart the process. Readiness reports the health checks
+// and reports down while aah server is starting or shutting down. Response
+// status is `503 Service Unavailable` if report is down.
+//
+// Note: aah server stops accepting new connections on graceful shutdown, so
+// readiness down during shutdown is observable only on separate health
+// listener `server.health.address`.
+func (h *health) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	var report *HealthReport
+	switch {
+	case r.URL.Path == h.livenessPath:
+		report = &HealthReport{Status: HealthStatusUp}
+	case r.URL.Path == h.readinessPath && !AppIsReady():
+		report = &HealthReport{Status: HealthStatusDown, Message: "server is not ready"}
+	case r.URL.Path == h.readinessPath:
+		report = h.check(r.Context())
+	default:
+		http.NotFound(w, r)
+		return
+	}
+
+	w.Header().Set(ahttp.HeaderContentType, ahttp.ContentTypeJSON.Raw())
+	w.Header().Set(headerCacheControl, "no-cache, no-store, must-revalidate")
+	if report.Status == HealthStatusUp {
+		w.WriteHeader(http.StatusOK)
+	} else {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}
+
+	if err := json.NewEncoder(w).Encode(report); err != nil {
+		log.Error("Health report write error: ", err)
+	}
+}
+
+// check method runs the registered health checks concurrently and returns
+// the report, results are in the order of registration.
+func (h *health) check(c context.Context) *HealthReport {
+	appHealthChecksMu.Lock()
+	checks := make([]healthCheck, len(appHealthChecks))
+	for idx, hc := range appHealthChecks {
+		checks[idx] = *hc
+	}
+	appHealthChecksMu.Unlock()
+
+	report := &HealthReport{Status: HealthStatusUp, Checks: make([]*HealthCheckResult, len(checks))}
+	wg := sync.WaitGroup{}
+	for idx, hc := range checks {
+		wg.Add(1)
+		go func(idx int, hc healthCheck) {
+			defer wg.Done()
+			report.Checks[idx] = h.run(c, hc)
+		}(idx, hc)
+	}
+	wg.Wait()
+
+	for _, result := range report.Checks {
+		if result.Status != HealthStatusUp {
+			report.Status = HealthStatusDown
+		}
+	}
+	return report
+}
+
+// run method runs the health check with timeout. Check which doesn't
+// return within timeout is reported down, it's left to finish on its own.
+func (h *health) run(c context.Context, hc healthCheck) *HealthCheckResult {
+	c, cancel := context.WithTimeout(c, h.timeout)
+	defer cancel()
+
+	start := time.Now()
+	done := make(chan error, 1)
+	go func() {
+		defer func() {
+			if r := recover(); r != nil {
+				done <- fmt.Errorf("panic: %v", r)
+			}
+		}()
+		done <- hc.check(c)
+	}()
+
+	var err error
+	select {
+	case err = <-done:
+	case <-c.Done():
+		err = fmt.Errorf("timed out after %s", h.timeout)
+	}
+
+	result := &HealthCheckResult{Name: hc.name, Status: HealthStatusUp, Latency: time.Since(start).String()}
+	if err != nil {
+		result.Status, result.Error = HealthStatusDown, err.Error()
+	}
+	return result
+}
+
+//‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾
+// Unexported methods
+//___________________________________
+
+// isHealthRequest method returns true if health endpoints are served on aah
+// server and request path is one of them.
+func isHealthRequest(r *http.Request) bool {
+	return appHealth != nil && ess.IsStrEmpty(appHealth.address) &&
+		(r.URL.Path == appHealth.livenessPath || r.URL.Path == appHealth.readinessPath)
+}
+
+func setAppReady(ready bool) {
+	if ready {
+		atomic.StoreInt32(&appReady, 1)
+	} else {
+		atomic.StoreInt32(&appReady, 0)
+	}
+}
+
+// startHealthServer method starts the separate health listener if
+// `server.health.address` is configured.
+func startHealthServer() {
+	if appHealth == nil || ess.IsStrEmpty(appHealth.address) {
+		return
+	}
+
+	mux := http.NewServeMux()
+	mux.Handle(appHealth.livenessPath, appHealth)
+	mux.Handle(appHealth.readinessPath, appHealth)
+	appHealthServer = &http.Server{Addr: appHealth.address, Handler: mux}
+
+	go func() {
+		log.Infof("aah health server running on %v", appHealth.address)
+		if err := appHealthServer.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+			log.Error(err)
+		}
+	}()
+}
+
+// closeHealthServer method closes the separate health listener.
+func closeHealthServer() {
+	if appHealthServer != nil {
+		if err := appHealthServer.Close(); err != nil {
+			log.Error(err)
+		}
+		appHealthServer = nil
+	}
+}
+
+// initHealth method initializes the health endpoints from aah.conf
+// `server.health`.
+func initHealth(appCfg *config.Config) error {
+	appHealth = nil
+	if !appCfg.BoolDefault("server.health.enable", false) {
+		return nil
+	}
+
+	h := &health{
+		livenessPath:  appCfg.StringDefault("server.health.liveness_path", defaultHealthLivenessPath),
+		readinessPath: appCfg.StringDefault("server.health.readiness_path", defaultHealthReadinessPath),
+		address:       appCfg.StringDefault("server.health.address", ""),
+	}
+
+	if !strings.HasPrefix(h.livenessPath, "/") {
+		return fmt.Errorf("'server.health.liveness_path' value '%s' must start with '/'", h.livenessPath)
+	}
+	if !strings.HasPrefix(h.readinessPath, "/") {
+		return fmt.Errorf("'server.health.readiness_path' value '%s' must start with '/'", h.readinessPath)
+	}
+
+	timeout, err := time.ParseDuration(appCfg.StringDefault("server.health.timeout", "5s"))
+	if err != nil {
+		return fmt.Errorf("'server.health.timeout' value is not a valid time unit: %s", err)
+	}
+	h.timeout = timeout
+
+	appHealth = h
+	return nil
+}
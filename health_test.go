@@ -0,0 +1,154 @@
+// Copyright (c) Jeevanandam M. (https://github.com/jeevatkm)
+// go-aah/aah source code and usage is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package aah
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"aahframework.org/config.v0"
+	"aahframework.org/test.v0/assert"
+)
+
+func TestHealthInit(t *testing.T) {
+	defer func() { appHealth = nil }()
+
+	cfg, _ := config.ParseString("")
+	assert.Nil(t, initHealth(cfg))
+	assert.Nil(t, appHealth)
+	assert.False(t, isHealthRequest(httptest.NewRequest("GET", "http://localhost:8080/healthz", nil)))
+
+	cfg.SetBool("server.health.enable", true)
+	assert.Nil(t, initHealth(cfg))
+	assert.Equal(t, "/healthz", appHealth.livenessPath)
+	assert.Equal(t, "/readyz", appHealth.readinessPath)
+	assert.Equal(t, 5*time.Second, appHealth.timeout)
+	assert.True(t, isHealthRequest(httptest.NewRequest("GET", "http://localhost:8080/healthz", nil)))
+	assert.True(t, isHealthRequest(httptest.NewRequest("GET", "http://localhost:8080/readyz", nil)))
+	assert.False(t, isHealthRequest(httptest.NewRequest("GET", "http://localhost:8080/users", nil)))
+
+	// separate listener
+	cfg.SetString("server.health.address", ":8081")
+	assert.Nil(t, initHealth(cfg))
+	assert.Equal(t, ":8081", appHealth.address)
+	assert.False(t, isHealthRequest(httptest.NewRequest("GET", "http://localhost:8080/healthz", nil)))
+
+	cfg.SetString("server.health.timeout", "5")
+	err := initHealth(cfg)
+	assert.True(t, strings.HasPrefix(err.Error(), "'server.health.timeout' value is not a valid time unit: "))
+	assert.Nil(t, appHealth)
+
+	cfg.SetString("server.health.readiness_path", "readyz")
+	assert.Equal(t, "'server.health.readiness_path' value 'readyz' must start with '/'", initHealth(cfg).Error())
+
+	cfg.SetString("server.health.liveness_path", "healthz")
+	assert.Equal(t, "'server.health.liveness_path' value 'healthz' must start with '/'", initHealth(cfg).Error())
+}
+
+func TestHealthChecks(t *testing.T) {
+	defer func() { appHealthChecks = nil }()
+
+	h := &health{livenessPath: "/healthz", readinessPath: "/readyz", timeout: 50 * time.Millisecond}
+	report := h.check(context.Background())
+	assert.Equal(t, HealthStatusUp, report.Status)
+	assert.Equal(t, 0, len(report.Checks))
+
+	AddHealthCheck("", nil)
+	AddHealthCheck("database", func(ctx context.Context) error { return errors.New("connection refused") })
+	AddHealthCheck("cache", func(ctx context.Context) error { return nil })
+	AddHealthCheck("queue", func(ctx context.Context) error {
+		<-ctx.Done()
+		return ctx.Err()
+	})
+	AddHealthCheck("search", func(ctx context.Context) error { panic("index missing") })
+	assert.Equal(t, 4, len(appHealthChecks))
+
+	report = h.check(context.Background())
+	assert.Equal(t, HealthStatusDown, report.Status)
+	assert.Equal(t, 4, len(report.Checks))
+	assert.Equal(t, "database", report.Checks[0].Name)
+	assert.Equal(t, HealthStatusDown, report.Checks[0].Status)
+	assert.Equal(t, "connection refused", report.Checks[0].Error)
+	assert.Equal(t, HealthStatusUp, report.Checks[1].Status)
+	assert.Equal(t, "", report.Checks[1].Error)
+	assert.Equal(t, HealthStatusDown, report.Checks[2].Status)
+	assert.Equal(t, "timed out after 50ms", report.Checks[2].Error)
+	assert.Equal(t, "panic: index missing", report.Checks[3].Error)
+
+	latency, err := time.ParseDuration(report.Checks[2].Latency)
+	assert.Nil(t, err)
+	assert.True(t, latency >= 50*time.Millisecond)
+
+	// replaced by name
+	AddHealthCheck("database", func(ctx context.Context) error { return nil })
+	assert.Equal(t, 4, len(appHealthChecks))
+	assert.Equal(t, HealthStatusUp, h.check(context.Background()).Checks[0].Status)
+}
+
+func TestHealthEngine(t *testing.T) {
+	defer func() {
+		appHealth, appHealthChecks = nil, nil
+		setAppReady(false)
+	}()
+
+	appConfig, _ = config.ParseString("")
+	appConfig.SetBool("server.health.enable", true)
+	err := initSecurity(filepath.Join(getTestdataPath(), appConfigDir()), appConfig)
+	assert.FailNowOnError(t, err, "")
+	assert.Nil(t, initHealth(appConfig))
+	e := newEngine(appConfig)
+
+	AddHealthCheck("database", func(ctx context.Context) error { return nil })
+
+	serve := func(path string) (int, *HealthReport) {
+		w := httptest.NewRecorder()
+		e.ServeHTTP(w, httptest.NewRequest("GET", "http://localhost:8080"+path, nil))
+		assert.Equal(t, "application/json; charset=utf-8", w.Header().Get("Content-Type"))
+		report := &HealthReport{}
+		assert.Nil(t, json.Unmarshal(w.Body.Bytes(), report))
+		return w.Code, report
+	}
+
+	// before the server listener is up
+	setAppReady(false)
+	code, report := serve("/readyz")
+	assert.Equal(t, http.StatusServiceUnavailable, code)
+	assert.Equal(t, HealthStatusDown, report.Status)
+	assert.Equal(t, "server is not ready", report.Message)
+
+	// liveness doesn't run the checks
+	code, report = serve("/healthz")
+	assert.Equal(t, http.StatusOK, code)
+	assert.Equal(t, HealthStatusUp, report.Status)
+	assert.Equal(t, 0, len(report.Checks))
+
+	setAppReady(true)
+	assert.True(t, AppIsReady())
+	code, report = serve("/readyz")
+	assert.Equal(t, http.StatusOK, code)
+	assert.Equal(t, 1, len(report.Checks))
+
+	// failing health check
+	AddHealthCheck("database", func(ctx context.Context) error { return errors.New("connection refused") })
+	code, report = serve("/readyz")
+	assert.Equal(t, http.StatusServiceUnavailable, code)
+	assert.Equal(t, "connection refused", report.Checks[0].Error)
+
+	code, report = serve("/healthz")
+	assert.Equal(t, http.StatusOK, code)
+	assert.Equal(t, HealthStatusUp, report.Status)
+
+	// separate listener serves only health paths
+	w := httptest.NewRecorder()
+	appHealth.ServeHTTP(w, httptest.NewRequest("GET", "http://localhost:8081/users", nil))
+	assert.Equal(t, http.StatusNotFound, w.Code)
+}
@@ -0,0 +1,185 @@
+// Copyright (c) Jeevanandam M. (https://github.com/jeevatkm)
+// go-aah/aah source code and usage is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package aah
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"unicode/utf16"
+	"unicode/utf8"
+
+	"aahframework.org/config.v0"
+	"aahframework.org/essentials.v0"
+)
+
+const defaultJSONPrettyIndent = "    "
+
+var (
+	appJSONOptions             = &JSONOptions{EscapeHTML: true}
+	appJSONEncoder JSONEncoder = &stdJSONEncoder{}
+)
+
+type (
+	// JSONOptions holds the options of JSON render. Application options are
+	// configured via aah.conf `render.json.*`, per reply options can be given
+	// via `Reply().JSONWithOptions`.
+	JSONOptions struct {
+		// EscapeHTML escapes the HTML characters `<`, `>` and `&` in the
+		// JSON strings.
+		EscapeHTML bool
+
+		// Indent is the indent string of pretty JSON, empty renders compact JSON.
+		Indent string
+
+		// SecurePrefix is written before the JSON to protect from JSON
+		// hijacking, e.g. `)]}',\n`. It's applied only to `application/json`
+		// data replies, not for JSONP, problem details and framework error
+		// replies.
+		SecurePrefix string
+
+		// ASCII escapes the non-ASCII characters as `\uXXXX`.
+		ASCII bool
+	}
+
+	// JSONEncoder interface is to plug-in alternate JSON library for JSON
+	// render, register it via `aah.SetJSONEncoder`.
+	JSONEncoder interface {
+		NewEncoder(w io.Writer) JSONStreamEncoder
+	}
+
+	// JSONStreamEncoder interface is the subset of `encoding/json.Encoder`
+	// methods used by JSON render.
+	JSONStreamEncoder interface {
+		Encode(v interface{}) error
+		SetEscapeHTML(on bool)
+		SetIndent(prefix, indent string)
+	}
+
+	// stdJSONEncoder is the default JSON encoder, it uses `encoding/json`.
+	stdJSONEncoder struct{}
+)
+
+//‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾
+// Global methods
+//___________________________________
+
+// SetJSONEncoder method sets the given JSON encoder for JSON render, by
+// default `encoding/json` is used.
+//		For Example:
+//
+//		type jsoniterEncoder struct{}
+//
+//		func (jsoniterEncoder) NewEncoder(w io.Writer) aah.JSONStreamEncoder {
+//			return jsoniter.ConfigCompatibleWithStandardLibrary.NewEncoder(w)
+//		}
+//
+//		aah.SetJSONEncoder(jsoniterEncoder{})
+func SetJSONEncoder(encoder JSONEncoder) {
+	if encoder == nil {
+		encoder = &stdJSONEncoder{}
+	}
+	appJSONEncoder = encoder
+}
+
+// AppJSONOptions method returns the copy of application JSON options from
+// aah.conf, it's handy to compose the per reply options.
+//		For Example:
+//
+//		opts := aah.AppJSONOptions()
+//		opts.SecurePrefix = ")]}',\n"
+//		c.Reply().JSONWithOptions(data, &opts)
+func AppJSONOptions() JSONOptions {
+	return *appJSONOptions
+}
+
+//‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾
+// stdJSONEncoder methods
+//___________________________________
+
+// NewEncoder method returns the `encoding/json` encoder for the writer.
+func (s *stdJSONEncoder) NewEncoder(w io.Writer) JSONStreamEncoder {
+	return json.NewEncoder(w)
+}
+
+//‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾
+// Unexported methods
+//___________________________________
+
+// encodeJSON method encodes the given value as per options via registered
+// JSON encoder, trailing newline of encoder is trimmed.
+func encodeJSON(v interface{}, opts *JSONOptions) ([]byte, error) {
+	buf := &bytes.Buffer{}
+	encoder := appJSONEncoder.NewEncoder(buf)
+	encoder.SetEscapeHTML(opts.EscapeHTML)
+	if !ess.IsStrEmpty(opts.Indent) {
+		encoder.SetIndent("", opts.Indent)
+	}
+
+	if err := encoder.Encode(v); err != nil {
+		return nil, err
+	}
+
+	b := bytes.TrimSuffix(buf.Bytes(), []byte("\n"))
+	if opts.ASCII {
+		b = asciiJSON(b)
+	}
+	return b, nil
+}
+
+// unprefixedJSONOptions method returns the application JSON options without
+// secure prefix, for problem details and framework error replies.
+func unprefixedJSONOptions() *JSONOptions {
+	opts := AppJSONOptions()
+	opts.SecurePrefix = ""
+	return &opts
+}
+
+// asciiJSON method escapes the non-ASCII characters of the JSON as `\uXXXX`,
+// characters beyond BMP are escaped as UTF-16 surrogate pair. Non-ASCII
+// characters appears only within JSON strings, so it's safe to escape all.
+func asciiJSON(b []byte) []byte {
+	buf := bytes.NewBuffer(make([]byte, 0, len(b)))
+	for len(b) > 0 {
+		r, size := utf8.DecodeRune(b)
+		b = b[size:]
+		if r < utf8.RuneSelf {
+			buf.WriteByte(byte(r))
+			continue
+		}
+
+		if r1, r2 := utf16.EncodeRune(r); r1 != utf8.RuneError {
+			fmt.Fprintf(buf, `\u%04x\u%04x`, r1, r2)
+		} else {
+			fmt.Fprintf(buf, `\u%04x`, r)
+		}
+	}
+	return buf.Bytes()
+}
+
+// initJSONOptions method initializes the application JSON options from
+// aah.conf `render.json.*`. Indent defaults to four spaces if
+// `render.pretty` is true.
+func initJSONOptions(appCfg *config.Config) error {
+	indent := appCfg.StringDefault("render.json.indent", "")
+	if ess.IsStrEmpty(indent) && appCfg.BoolDefault("render.pretty", false) {
+		indent = defaultJSONPrettyIndent
+	}
+
+	if strings.Trim(indent, " \t") != "" {
+		return errors.New("'render.json.indent' value must be spaces or tabs")
+	}
+
+	appJSONOptions = &JSONOptions{
+		EscapeHTML:   appCfg.BoolDefault("render.json.escape_html", true),
+		Indent:       indent,
+		SecurePrefix: appCfg.StringDefault("render.json.secure_prefix", ""),
+		ASCII:        appCfg.BoolDefault("render.json.ascii", false),
+	}
+	return nil
+}
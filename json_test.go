@@ -0,0 +1,117 @@
+// Copyright (c) Jeevanandam M. (https://github.com/jeevatkm)
+// go-aah/aah source code and usage is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package aah
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"aahframework.org/ahttp.v0"
+	"aahframework.org/config.v0"
+	"aahframework.org/test.v0/assert"
+)
+
+type testCountingEncoder struct {
+	calls int
+}
+
+func (c *testCountingEncoder) NewEncoder(w io.Writer) JSONStreamEncoder {
+	c.calls++
+	return json.NewEncoder(w)
+}
+
+func TestJSONInitOptions(t *testing.T) {
+	defer func() { appJSONOptions = &JSONOptions{EscapeHTML: true} }()
+
+	cfg, _ := config.ParseString("")
+	assert.Nil(t, initJSONOptions(cfg))
+	assert.Equal(t, JSONOptions{EscapeHTML: true}, AppJSONOptions())
+
+	cfg.SetBool("render.pretty", true)
+	assert.Nil(t, initJSONOptions(cfg))
+	assert.Equal(t, "    ", AppJSONOptions().Indent)
+
+	cfg.SetString("render.json.indent", "\t")
+	cfg.SetBool("render.json.escape_html", false)
+	cfg.SetString("render.json.secure_prefix", ")]}',")
+	cfg.SetBool("render.json.ascii", true)
+	assert.Nil(t, initJSONOptions(cfg))
+	assert.Equal(t, JSONOptions{Indent: "\t", SecurePrefix: ")]}',", ASCII: true}, AppJSONOptions())
+
+	cfg.SetString("render.json.indent", "--")
+	assert.Equal(t, "'render.json.indent' value must be spaces or tabs", initJSONOptions(cfg).Error())
+}
+
+func TestJSONRenderOptions(t *testing.T) {
+	data := Data{"html": "<b>aah & go</b>", "city": "Zürich 🚀"}
+	render := func(opts *JSONOptions, jsonp bool) string {
+		buf := &bytes.Buffer{}
+		err := (&JSON{Data: data, Options: opts, IsJSONP: jsonp, Callback: "cb"}).Render(buf)
+		assert.FailOnError(t, err, "")
+		return buf.String()
+	}
+
+	assert.Equal(t, `{"city":"Zürich 🚀","html":"\u003cb\u003eaah \u0026 go\u003c/b\u003e"}`,
+		render(&JSONOptions{EscapeHTML: true}, false))
+	assert.Equal(t, `{"city":"Zürich 🚀","html":"<b>aah & go</b>"}`,
+		render(&JSONOptions{}, false))
+	assert.Equal(t, `{"city":"Z\u00fcrich \ud83d\ude80","html":"<b>aah & go</b>"}`,
+		render(&JSONOptions{ASCII: true}, false))
+	assert.Equal(t, "{\n  \"city\": \"Zürich 🚀\",\n  \"html\": \"<b>aah & go</b>\"\n}",
+		render(&JSONOptions{Indent: "  "}, false))
+
+	// secure prefix is not applicable for JSONP
+	secure := &JSONOptions{SecurePrefix: ")]}',\n"}
+	assert.Equal(t, ")]}',\n{\"city\":\"Zürich 🚀\",\"html\":\"<b>aah & go</b>\"}", render(secure, false))
+	assert.Equal(t, `cb({"city":"Zürich 🚀","html":"<b>aah & go</b>"});`, render(secure, true))
+
+	// per reply options
+	re := NewReply().JSONWithOptions(data, secure)
+	assert.Equal(t, secure, re.Rdr.(*JSON).Options)
+}
+
+func TestJSONSetEncoder(t *testing.T) {
+	encoder := &testCountingEncoder{}
+	SetJSONEncoder(encoder)
+	defer SetJSONEncoder(nil)
+
+	buf := &bytes.Buffer{}
+	err := (&JSON{Data: Data{"name": "aah"}}).Render(buf)
+	assert.FailOnError(t, err, "")
+	assert.Equal(t, `{"name":"aah"}`, buf.String())
+	assert.Equal(t, 1, encoder.calls)
+
+	err = (&JSON{Data: make(chan int)}).Render(buf)
+	assert.NotNil(t, err)
+}
+
+func TestJSONSecurePrefixScope(t *testing.T) {
+	appJSONOptions = &JSONOptions{EscapeHTML: true, SecurePrefix: ")]}',\n"}
+	defer func() { appJSONOptions = &JSONOptions{EscapeHTML: true} }()
+	appConfig, _ = config.ParseString("")
+
+	render := func(r Render) string {
+		buf := &bytes.Buffer{}
+		assert.Nil(t, r.Render(buf))
+		return buf.String()
+	}
+
+	// data reply
+	assert.Equal(t, ")]}',\n{\"name\":\"aah\"}", render(NewReply().JSON(Data{"name": "aah"}).Rdr))
+
+	// problem details and framework error replies
+	assert.Equal(t, `{"status":404,"title":"Not Found"}`,
+		render(&Problem{Status: http.StatusNotFound, Title: "Not Found"}))
+
+	req := httptest.NewRequest("GET", "http://localhost:8080/users/1001", nil)
+	req.Header.Set(ahttp.HeaderAccept, ahttp.ContentTypeJSON.Raw())
+	ctx := &Context{Req: ahttp.ParseRequest(req, &ahttp.Request{}), reply: NewReply()}
+	handleError(ctx, &HTTPError{Code: http.StatusNotFound})
+	assert.Equal(t, `{"code":404,"message":"Not Found"}`, render(ctx.Reply().Rdr))
+}
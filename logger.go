@@ -0,0 +1,181 @@
+// Copyright (c) Jeevanandam M. (https://github.com/jeevatkm)
+// go-aah/aah source code and usage is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package aah
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+
+	"aahframework.org/essentials.v0"
+	"aahframework.org/log.v0"
+)
+
+const requestIDContextKey requestLogContextKey = iota
+
+var appRequestLogWriter requestLogWriter = &stdRequestLogWriter{}
+
+type (
+	requestLogContextKey int
+
+	// RequestLogger is request-scoped logger, it stamps the request ID, route
+	// name, controller action and client IP on every log entry. It's obtained
+	// via `ctx.Log()`.
+	RequestLogger struct {
+		ctx *Context
+	}
+
+	// requestLogWriter is the writer of request log entries, it's satisfied
+	// by `log.Logger`.
+	requestLogWriter interface {
+		Error(v ...interface{})
+		Warn(v ...interface{})
+		Info(v ...interface{})
+		Debug(v ...interface{})
+		Trace(v ...interface{})
+		IsLevelDebug() bool
+		IsLevelTrace() bool
+	}
+
+	// stdRequestLogWriter writes the request log entries via aah default
+	// logger.
+	stdRequestLogWriter struct{}
+)
+
+//‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾
+// Global methods
+//___________________________________
+
+// RequestIDFromContext method returns the request ID from the request
+// `context.Context`, it's handy to correlate the outgoing calls and log
+// entries made with `ctx.Context()` outside of `ctx.Log()`.
+//		For Example:
+//
+//		func (s *UserService) Find(c context.Context, id string) (*User, error) {
+//			log.Debugf("reqid=%s finding user: %s", aah.RequestIDFromContext(c), id)
+//			...
+//		}
+func RequestIDFromContext(c context.Context) string {
+	if c == nil {
+		return ""
+	}
+	id, _ := c.Value(requestIDContextKey).(string)
+	return id
+}
+
+//‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾
+// RequestLogger methods
+//___________________________________
+
+// Error method logs the error entry.
+func (rl *RequestLogger) Error(v ...interface{}) {
+	appRequestLogWriter.Error(rl.entry(fmt.Sprint(v...)))
+}
+
+// Errorf method logs the error entry with format.
+func (rl *RequestLogger) Errorf(format string, v ...interface{}) {
+	appRequestLogWriter.Error(rl.entry(fmt.Sprintf(format, v...)))
+}
+
+// Warn method logs the warning entry.
+func (rl *RequestLogger) Warn(v ...interface{}) {
+	appRequestLogWriter.Warn(rl.entry(fmt.Sprint(v...)))
+}
+
+// Warnf method logs the warning entry with format.
+func (rl *RequestLogger) Warnf(format string, v ...interface{}) {
+	appRequestLogWriter.Warn(rl.entry(fmt.Sprintf(format, v...)))
+}
+
+// Info method logs the info entry.
+func (rl *RequestLogger) Info(v ...interface{}) {
+	appRequestLogWriter.Info(rl.entry(fmt.Sprint(v...)))
+}
+
+// Infof method logs the info entry with format.
+func (rl *RequestLogger) Infof(format string, v ...interface{}) {
+	appRequestLogWriter.Info(rl.entry(fmt.Sprintf(format, v...)))
+}
+
+// Debug method logs the debug entry. Entry is not formatted if log level
+// is above debug.
+func (rl *RequestLogger) Debug(v ...interface{}) {
+	if !appRequestLogWriter.IsLevelDebug() {
+		return
+	}
+	appRequestLogWriter.Debug(rl.entry(fmt.Sprint(v...)))
+}
+
+// Debugf method logs the debug entry with format.
+func (rl *RequestLogger) Debugf(format string, v ...interface{}) {
+	if !appRequestLogWriter.IsLevelDebug() {
+		return
+	}
+	appRequestLogWriter.Debug(rl.entry(fmt.Sprintf(format, v...)))
+}
+
+// Trace method logs the trace entry. Entry is not formatted if log level
+// is above trace.
+func (rl *RequestLogger) Trace(v ...interface{}) {
+	if !appRequestLogWriter.IsLevelTrace() {
+		return
+	}
+	appRequestLogWriter.Trace(rl.entry(fmt.Sprint(v...)))
+}
+
+// Tracef method logs the trace entry with format.
+func (rl *RequestLogger) Tracef(format string, v ...interface{}) {
+	if !appRequestLogWriter.IsLevelTrace() {
+		return
+	}
+	appRequestLogWriter.Trace(rl.entry(fmt.Sprintf(format, v...)))
+}
+
+// entry method returns the log entry with request fields prefixed, fields
+// are resolved at the time of logging since route and action are known later
+// in the request pipeline. Empty fields are omitted.
+//		For Example:
+//
+//		reqid=5946ed129bf23409520736de route=list_users action=User.List clientip=10.0.0.1 message
+func (rl *RequestLogger) entry(msg string) string {
+	ctx := rl.ctx
+	buf := &bytes.Buffer{}
+	writeLogField := func(key, value string) {
+		if !ess.IsStrEmpty(value) {
+			buf.WriteString(key)
+			buf.WriteByte('=')
+			buf.WriteString(value)
+			buf.WriteByte(' ')
+		}
+	}
+
+	if ctx.Req != nil {
+		writeLogField("reqid", ctx.Req.Header.Get(appRequestIDHeader))
+	}
+	if ctx.route != nil {
+		writeLogField("route", ctx.route.Name)
+	}
+	if ctx.action != nil {
+		writeLogField("action", ctx.controller+"."+ctx.action.Name)
+	}
+	if ctx.Req != nil {
+		writeLogField("clientip", ctx.Req.ClientIP)
+	}
+
+	buf.WriteString(msg)
+	return buf.String()
+}
+
+//‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾
+// stdRequestLogWriter methods
+//___________________________________
+
+func (s *stdRequestLogWriter) Error(v ...interface{}) { log.Error(v...) }
+func (s *stdRequestLogWriter) Warn(v ...interface{})  { log.Warn(v...) }
+func (s *stdRequestLogWriter) Info(v ...interface{})  { log.Info(v...) }
+func (s *stdRequestLogWriter) Debug(v ...interface{}) { log.Debug(v...) }
+func (s *stdRequestLogWriter) Trace(v ...interface{}) { log.Trace(v...) }
+func (s *stdRequestLogWriter) IsLevelDebug() bool     { return log.IsLevelDebug() }
+func (s *stdRequestLogWriter) IsLevelTrace() bool     { return log.IsLevelTrace() }
@@ -0,0 +1,92 @@
+// Copyright (c) Jeevanandam M. (https://github.com/jeevatkm)
+// go-aah/aah source code and usage is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package aah
+
+import (
+	"context"
+	"fmt"
+	"net/http/httptest"
+	"testing"
+
+	"aahframework.org/config.v0"
+	"aahframework.org/router.v0"
+	"aahframework.org/test.v0/assert"
+)
+
+type testRequestLogWriter struct {
+	entries []string
+	quiet   bool
+}
+
+func (w *testRequestLogWriter) write(level string, v []interface{}) {
+	w.entries = append(w.entries, level+" "+fmt.Sprint(v...))
+}
+
+func (w *testRequestLogWriter) Error(v ...interface{}) { w.write("ERROR", v) }
+func (w *testRequestLogWriter) Warn(v ...interface{})  { w.write("WARN", v) }
+func (w *testRequestLogWriter) Info(v ...interface{})  { w.write("INFO", v) }
+func (w *testRequestLogWriter) Debug(v ...interface{}) { w.write("DEBUG", v) }
+func (w *testRequestLogWriter) Trace(v ...interface{}) { w.write("TRACE", v) }
+func (w *testRequestLogWriter) IsLevelDebug() bool     { return !w.quiet }
+func (w *testRequestLogWriter) IsLevelTrace() bool     { return !w.quiet }
+
+func TestLoggerRequestLog(t *testing.T) {
+	writer := &testRequestLogWriter{}
+	appRequestLogWriter = writer
+	defer func() { appRequestLogWriter = &stdRequestLogWriter{} }()
+
+	cfg, _ := config.ParseString("")
+	e := newEngine(cfg)
+
+	r := httptest.NewRequest("GET", "http://localhost:8080/users", nil)
+	r.RemoteAddr = "10.0.0.1"
+	r.Header.Set("X-Request-Id", "5946ed129bf23409520736de")
+	ctx := e.prepareContext(httptest.NewRecorder(), r)
+	assert.Equal(t, ctx.Log(), ctx.Log())
+
+	// route and action are not known yet
+	e.setRequestID(ctx)
+	assert.Equal(t, "5946ed129bf23409520736de", RequestIDFromContext(ctx.Context()))
+
+	ctx.route = &router.Route{Name: "list_users"}
+	ctx.controller, ctx.action = "User", &MethodInfo{Name: "List"}
+	ctx.Log().Error("list failed: ", "db timeout")
+	ctx.Log().Errorf("list failed: %s", "db timeout")
+	ctx.Log().Warn("slow")
+	ctx.Log().Warnf("slow %dms", 500)
+	ctx.Log().Info("listed")
+	ctx.Log().Infof("listed %d users", 10)
+	ctx.Log().Debug("page 1")
+	ctx.Log().Debugf("page %d", 2)
+	ctx.Log().Trace("done")
+	ctx.Log().Tracef("done %s", "ok")
+
+	prefix := "reqid=5946ed129bf23409520736de route=list_users action=User.List clientip=10.0.0.1 "
+	assert.Equal(t, []string{
+		"DEBUG reqid=5946ed129bf23409520736de clientip=10.0.0.1 Request already has ID: 5946ed129bf23409520736de",
+		"ERROR " + prefix + "list failed: db timeout",
+		"ERROR " + prefix + "list failed: db timeout",
+		"WARN " + prefix + "slow",
+		"WARN " + prefix + "slow 500ms",
+		"INFO " + prefix + "listed",
+		"INFO " + prefix + "listed 10 users",
+		"DEBUG " + prefix + "page 1",
+		"DEBUG " + prefix + "page 2",
+		"TRACE " + prefix + "done",
+		"TRACE " + prefix + "done ok",
+	}, writer.entries)
+
+	// log level is above debug
+	writer.quiet = true
+	ctx.Log().Debugf("page %d", 3)
+	ctx.Log().Trace("done")
+	assert.Equal(t, 11, len(writer.entries))
+
+	// released context
+	ctx.Reset()
+	assert.Nil(t, ctx.logger)
+	assert.Equal(t, "", RequestIDFromContext(context.Background()))
+	assert.Equal(t, "", RequestIDFromContext(nil))
+}
@@ -0,0 +1,354 @@
+// Copyright (c) Jeevanandam M. (https://github.com/jeevatkm)
+// go-aah/aah source code and usage is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package aah
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"aahframework.org/ahttp.v0"
+	"aahframework.org/config.v0"
+	"aahframework.org/essentials.v0"
+	"aahframework.org/log.v0"
+)
+
+const (
+	poolContext = iota
+	poolRequest
+	poolReply
+	poolBuffer
+)
+
+const (
+	defaultMetricsPath       = "/metrics"
+	metricsUnknownRoute      = "unknown"
+	metricsOtherMethod       = "other"
+	metricsContentType       = "text/plain; version=0.0.4; charset=utf-8"
+	metricsNamePrefix        = "aah_"
+	metricsLabelRoute        = "route"
+	metricsLabelMethod       = "method"
+	metricsLabelStatus       = "status"
+	metricsLabelPool         = "pool"
+	metricsHistogramInfBound = "+Inf"
+)
+
+var (
+	appMetrics       *metrics
+	appMetricsServer *http.Server
+
+	poolNames = []string{"ctx", "req", "reply", "buf"}
+
+	defaultLatencyBuckets = []float64{.005, .01, .025, .05, .1, .25, .5, 1, 2.5, 5, 10}
+	defaultSizeBuckets    = []float64{100, 1000, 10000, 100000, 1000000, 10000000}
+)
+
+type (
+	// metrics collects the request and engine metrics of aah server, it's
+	// exposed in Prometheus text exposition format.
+	metrics struct {
+		// atomic counters are kept first for 64-bit alignment
+		inFlight int64
+		gzip     uint64
+		panics   uint64
+		poolGets [4]uint64
+		poolMiss [4]uint64
+		path     string
+		address  string
+
+		mu        sync.Mutex
+		requests  map[string]uint64
+		latencies map[string]*histogram
+		sizes     map[string]*histogram
+	}
+
+	// histogram is cumulative histogram of observed values with upper bounds.
+	histogram struct {
+		bounds []float64
+		counts []uint64
+		sum    float64
+		count  uint64
+	}
+)
+
+//‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾
+// metrics methods
+//___________________________________
+
+// ServeHTTP method writes the metrics in Prometheus text exposition format.
+func (m *metrics) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", metricsContentType)
+	if _, err := m.WriteTo(w); err != nil {
+		log.Error("Metrics write error: ", err)
+	}
+}
+
+// WriteTo method writes the metrics in Prometheus text exposition format
+// into given writer.
+func (m *metrics) WriteTo(w io.Writer) (int64, error) {
+	buf := &bytes.Buffer{}
+
+	writeMetricHeader(buf, "http_requests_total", "counter", "Total number of HTTP requests by route, method and status class.")
+	m.mu.Lock()
+	for _, key := range sortedKeys(m.requests) {
+		fmt.Fprintf(buf, "%shttp_requests_total{%s} %d\n", metricsNamePrefix, key, m.requests[key])
+	}
+
+	writeMetricHeader(buf, "http_request_duration_seconds", "histogram", "HTTP request latency in seconds by route and method.")
+	for _, key := range sortedHistogramKeys(m.latencies) {
+		m.latencies[key].write(buf, "http_request_duration_seconds", key)
+	}
+
+	writeMetricHeader(buf, "http_response_size_bytes", "histogram", "HTTP response size in bytes by route.")
+	for _, key := range sortedHistogramKeys(m.sizes) {
+		m.sizes[key].write(buf, "http_response_size_bytes", key)
+	}
+	m.mu.Unlock()
+
+	writeMetricHeader(buf, "http_requests_in_flight", "gauge", "Number of HTTP requests currently being served.")
+	fmt.Fprintf(buf, "%shttp_requests_in_flight %d\n", metricsNamePrefix, atomic.LoadInt64(&m.inFlight))
+
+	writeMetricHeader(buf, "pool_hits_total", "counter", "Total number of instances reused from the engine pools.")
+	for idx, name := range poolNames {
+		gets, misses := atomic.LoadUint64(&m.poolGets[idx]), atomic.LoadUint64(&m.poolMiss[idx])
+		if misses > gets { // counters are read while requests are in progress
+			misses = gets
+		}
+		fmt.Fprintf(buf, "%spool_hits_total{%s=%q} %d\n", metricsNamePrefix, metricsLabelPool, name, gets-misses)
+	}
+
+	writeMetricHeader(buf, "pool_misses_total", "counter", "Total number of instances created due to empty engine pools.")
+	for idx, name := range poolNames {
+		fmt.Fprintf(buf, "%spool_misses_total{%s=%q} %d\n", metricsNamePrefix, metricsLabelPool, name, atomic.LoadUint64(&m.poolMiss[idx]))
+	}
+
+	writeMetricHeader(buf, "gzip_responses_total", "counter", "Total number of gzip compressed responses.")
+	fmt.Fprintf(buf, "%sgzip_responses_total %d\n", metricsNamePrefix, atomic.LoadUint64(&m.gzip))
+
+	writeMetricHeader(buf, "panics_recovered_total", "counter", "Total number of panics recovered while handling requests.")
+	fmt.Fprintf(buf, "%spanics_recovered_total %d\n", metricsNamePrefix, atomic.LoadUint64(&m.panics))
+
+	return buf.WriteTo(w)
+}
+
+// observeRequest method records the request count, latency and response
+// size of the route.
+func (m *metrics) observeRequest(route, method string, status, size int, latency time.Duration) {
+	routeLabel := fmt.Sprintf("%s=%q,%s=%q", metricsLabelRoute, route, metricsLabelMethod, method)
+	requestLabel := fmt.Sprintf("%s,%s=\"%dxx\"", routeLabel, metricsLabelStatus, status/100)
+	sizeLabel := fmt.Sprintf("%s=%q", metricsLabelRoute, route)
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.requests[requestLabel]++
+
+	if _, found := m.latencies[routeLabel]; !found {
+		m.latencies[routeLabel] = newHistogram(defaultLatencyBuckets)
+	}
+	m.latencies[routeLabel].observe(latency.Seconds())
+
+	if _, found := m.sizes[sizeLabel]; !found {
+		m.sizes[sizeLabel] = newHistogram(defaultSizeBuckets)
+	}
+	m.sizes[sizeLabel].observe(float64(size))
+}
+
+//‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾
+// histogram methods
+//___________________________________
+
+// observe method adds the given value into histogram.
+func (h *histogram) observe(v float64) {
+	for idx, bound := range h.bounds {
+		if v <= bound {
+			h.counts[idx]++
+		}
+	}
+	h.sum += v
+	h.count++
+}
+
+// write method writes the histogram buckets, sum and count with given labels.
+func (h *histogram) write(w io.Writer, name, labels string) {
+	for idx, bound := range h.bounds {
+		fmt.Fprintf(w, "%s%s_bucket{%s,le=%q} %d\n", metricsNamePrefix, name, labels,
+			strconv.FormatFloat(bound, 'g', -1, 64), h.counts[idx])
+	}
+	fmt.Fprintf(w, "%s%s_bucket{%s,le=%q} %d\n", metricsNamePrefix, name, labels, metricsHistogramInfBound, h.count)
+	fmt.Fprintf(w, "%s%s_sum{%s} %s\n", metricsNamePrefix, name, labels, strconv.FormatFloat(h.sum, 'g', -1, 64))
+	fmt.Fprintf(w, "%s%s_count{%s} %d\n", metricsNamePrefix, name, labels, h.count)
+}
+
+//‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾
+// Unexported methods
+//___________________________________
+
+// isMetricsRequest method returns true if metrics is served by aah server and
+// request path is the metrics path.
+func isMetricsRequest(r *http.Request) bool {
+	return appMetrics != nil && ess.IsStrEmpty(appMetrics.address) && r.URL.Path == appMetrics.path
+}
+
+// metricsRequestStart method increments the in-flight requests.
+func metricsRequestStart() {
+	if appMetrics != nil {
+		atomic.AddInt64(&appMetrics.inFlight, 1)
+	}
+}
+
+// metricsRequestEnd method decrements the in-flight requests.
+func metricsRequestEnd() {
+	if appMetrics != nil {
+		atomic.AddInt64(&appMetrics.inFlight, -1)
+	}
+}
+
+// metricsObserveRequest method records the request metrics from the request
+// context, it's called once reply is written. Route name is used as label
+// instead of request path and unknown methods as `other` to keep the
+// cardinality low.
+func metricsObserveRequest(ctx *Context) {
+	if appMetrics == nil {
+		return
+	}
+
+	route := metricsUnknownRoute
+	if ctx.route != nil && !ess.IsStrEmpty(ctx.route.Name) {
+		route = ctx.route.Name
+	}
+
+	status, size := ctx.Reply().Code, 0
+	if ctx.Res != nil && ctx.Res.Status() > 0 {
+		status, size = ctx.Res.Status(), ctx.Res.BytesWritten()
+	}
+
+	appMetrics.observeRequest(route, metricsMethod(ctx.Req.Method), status, size, time.Since(ctx.startTime))
+}
+
+// metricsMethod method returns the method label of the request, unknown
+// HTTP method is labeled as `other` to keep the cardinality bounded.
+func metricsMethod(method string) string {
+	switch method {
+	case ahttp.MethodGet, ahttp.MethodHead, ahttp.MethodPost, ahttp.MethodPut,
+		ahttp.MethodPatch, ahttp.MethodDelete, ahttp.MethodOptions,
+		ahttp.MethodConnect, ahttp.MethodTrace:
+		return method
+	}
+	return metricsOtherMethod
+}
+
+// metricsPoolGet method counts the get from the engine pool.
+func metricsPoolGet(pool int) {
+	if appMetrics != nil {
+		atomic.AddUint64(&appMetrics.poolGets[pool], 1)
+	}
+}
+
+// metricsPoolMiss method counts the new instance created for the engine pool.
+func metricsPoolMiss(pool int) {
+	if appMetrics != nil {
+		atomic.AddUint64(&appMetrics.poolMiss[pool], 1)
+	}
+}
+
+// metricsGzip method counts the gzip compressed response.
+func metricsGzip() {
+	if appMetrics != nil {
+		atomic.AddUint64(&appMetrics.gzip, 1)
+	}
+}
+
+// metricsPanic method counts the panic recovered on request handling.
+func metricsPanic() {
+	if appMetrics != nil {
+		atomic.AddUint64(&appMetrics.panics, 1)
+	}
+}
+
+// startMetricsServer method starts the separate metrics listener, if
+// `server.metrics.address` is configured.
+func startMetricsServer() {
+	if appMetrics == nil || ess.IsStrEmpty(appMetrics.address) {
+		return
+	}
+
+	mux := http.NewServeMux()
+	mux.Handle(appMetrics.path, appMetrics)
+	appMetricsServer = &http.Server{Addr: appMetrics.address, Handler: mux}
+
+	go func() {
+		log.Infof("aah metrics server running on %v%v", appMetrics.address, appMetrics.path)
+		if err := appMetricsServer.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+			log.Error(err)
+		}
+	}()
+}
+
+// closeMetricsServer method closes the separate metrics listener.
+func closeMetricsServer() {
+	if appMetricsServer != nil {
+		if err := appMetricsServer.Close(); err != nil {
+			log.Error(err)
+		}
+		appMetricsServer = nil
+	}
+}
+
+func newHistogram(bounds []float64) *histogram {
+	return &histogram{bounds: bounds, counts: make([]uint64, len(bounds))}
+}
+
+func writeMetricHeader(w io.Writer, name, typ, help string) {
+	fmt.Fprintf(w, "# HELP %s%s %s\n# TYPE %s%s %s\n", metricsNamePrefix, name, help, metricsNamePrefix, name, typ)
+}
+
+func sortedKeys(m map[string]uint64) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func sortedHistogramKeys(m map[string]*histogram) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// initMetrics method initializes the metrics from aah.conf `server.metrics`.
+// Metrics are served on aah server with `path`, or on separate listener if
+// `address` is configured.
+func initMetrics(appCfg *config.Config) error {
+	appMetrics = nil
+	if !appCfg.BoolDefault("server.metrics.enable", false) {
+		return nil
+	}
+
+	path := appCfg.StringDefault("server.metrics.path", defaultMetricsPath)
+	if !strings.HasPrefix(path, "/") {
+		return fmt.Errorf("'server.metrics.path' value '%s' must start with '/'", path)
+	}
+
+	appMetrics = &metrics{
+		path:      path,
+		address:   appCfg.StringDefault("server.metrics.address", ""),
+		requests:  make(map[string]uint64),
+		latencies: make(map[string]*histogram),
+		sizes:     make(map[string]*histogram),
+	}
+	return nil
+}
@@ -0,0 +1,134 @@
+// Copyright (c) Jeevanandam M. (https://github.com/jeevatkm)
+// go-aah/aah source code and usage is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package aah
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"aahframework.org/config.v0"
+	"aahframework.org/router.v0"
+	"aahframework.org/test.v0/assert"
+)
+
+func TestMetricsInit(t *testing.T) {
+	defer func() { appMetrics = nil }()
+
+	cfg, _ := config.ParseString("")
+	assert.Nil(t, initMetrics(cfg))
+	assert.Nil(t, appMetrics)
+	assert.False(t, isMetricsRequest(httptest.NewRequest("GET", "http://localhost:8080/metrics", nil)))
+
+	cfg.SetBool("server.metrics.enable", true)
+	assert.Nil(t, initMetrics(cfg))
+	assert.Equal(t, "/metrics", appMetrics.path)
+	assert.True(t, isMetricsRequest(httptest.NewRequest("GET", "http://localhost:8080/metrics", nil)))
+
+	// separate listener
+	cfg.SetString("server.metrics.address", ":9090")
+	assert.Nil(t, initMetrics(cfg))
+	assert.Equal(t, ":9090", appMetrics.address)
+	assert.False(t, isMetricsRequest(httptest.NewRequest("GET", "http://localhost:8080/metrics", nil)))
+
+	cfg.SetString("server.metrics.path", "metrics")
+	assert.Equal(t, "'server.metrics.path' value 'metrics' must start with '/'", initMetrics(cfg).Error())
+	assert.Nil(t, appMetrics)
+}
+
+func TestMetricsWrite(t *testing.T) {
+	m := &metrics{
+		requests:  make(map[string]uint64),
+		latencies: make(map[string]*histogram),
+		sizes:     make(map[string]*histogram),
+	}
+	m.observeRequest("list_users", "GET", http.StatusOK, 512, 20*time.Millisecond)
+	m.observeRequest("list_users", "GET", http.StatusNotFound, 64, 3*time.Second)
+	m.observeRequest("list_users", "GET", http.StatusOK, 1024, 100*time.Millisecond)
+	m.inFlight, m.gzip, m.panics = 2, 5, 1
+	m.poolGets[poolContext], m.poolMiss[poolContext] = 10, 3
+
+	buf := &bytes.Buffer{}
+	_, err := m.WriteTo(buf)
+	assert.FailOnError(t, err, "")
+	out := buf.String()
+
+	for _, line := range []string{
+		"# TYPE aah_http_requests_total counter",
+		`aah_http_requests_total{route="list_users",method="GET",status="2xx"} 2`,
+		`aah_http_requests_total{route="list_users",method="GET",status="4xx"} 1`,
+		"# TYPE aah_http_request_duration_seconds histogram",
+		`aah_http_request_duration_seconds_bucket{route="list_users",method="GET",le="0.025"} 1`,
+		`aah_http_request_duration_seconds_bucket{route="list_users",method="GET",le="0.1"} 2`,
+		`aah_http_request_duration_seconds_bucket{route="list_users",method="GET",le="5"} 3`,
+		`aah_http_request_duration_seconds_bucket{route="list_users",method="GET",le="+Inf"} 3`,
+		`aah_http_request_duration_seconds_sum{route="list_users",method="GET"} 3.12`,
+		`aah_http_request_duration_seconds_count{route="list_users",method="GET"} 3`,
+		`aah_http_response_size_bytes_bucket{route="list_users",le="100"} 1`,
+		`aah_http_response_size_bytes_bucket{route="list_users",le="1000"} 2`,
+		`aah_http_response_size_bytes_sum{route="list_users"} 1600`,
+		"aah_http_requests_in_flight 2",
+		`aah_pool_hits_total{pool="ctx"} 7`,
+		`aah_pool_misses_total{pool="ctx"} 3`,
+		`aah_pool_hits_total{pool="buf"} 0`,
+		"aah_gzip_responses_total 5",
+		"aah_panics_recovered_total 1",
+	} {
+		assert.True(t, strings.Contains(out, line+"\n"))
+	}
+}
+
+func TestMetricsEngine(t *testing.T) {
+	defer func() { appMetrics = nil }()
+
+	appConfig, _ = config.ParseString("")
+	appConfig.SetBool("server.metrics.enable", true)
+	err := initSecurity(filepath.Join(getTestdataPath(), appConfigDir()), appConfig)
+	assert.FailNowOnError(t, err, "")
+	assert.Nil(t, initMetrics(appConfig))
+	e := newEngine(appConfig)
+
+	// pool and request
+	ctx := e.prepareContext(httptest.NewRecorder(), httptest.NewRequest("POST", "http://localhost:8080/users", nil))
+	ctx.route = &router.Route{Name: "create_user"}
+	ctx.Reply().Created().Text("created")
+	e.writeReply(ctx)
+	metricsObserveRequest(ctx)
+	assert.Equal(t, uint64(1), appMetrics.poolGets[poolContext])
+	assert.Equal(t, uint64(1), appMetrics.poolGets[poolReply])
+	assert.Equal(t, uint64(1), appMetrics.requests[`route="create_user",method="POST",status="2xx"`])
+	assert.Equal(t, uint64(1), appMetrics.sizes[`route="create_user"`].count)
+
+	// route not found
+	ctx = e.prepareContext(httptest.NewRecorder(), httptest.NewRequest("GET", "http://localhost:8080/unknown", nil))
+	ctx.Reply().NotFound()
+	metricsObserveRequest(ctx)
+	assert.Equal(t, uint64(1), appMetrics.requests[`route="unknown",method="GET",status="4xx"`])
+
+	// unknown method
+	ctx = e.prepareContext(httptest.NewRecorder(), httptest.NewRequest("PROPFIND", "http://localhost:8080/unknown", nil))
+	ctx.Reply().MethodNotAllowed()
+	metricsObserveRequest(ctx)
+	assert.Equal(t, uint64(1), appMetrics.requests[`route="unknown",method="other",status="4xx"`])
+
+	// panic
+	ctx = e.prepareContext(httptest.NewRecorder(), httptest.NewRequest("GET", "http://localhost:8080/users", nil))
+	func() {
+		defer e.handleRecovery(ctx)
+		panic("metrics panic")
+	}()
+	assert.Equal(t, uint64(1), appMetrics.panics)
+
+	// metrics path
+	w := httptest.NewRecorder()
+	e.ServeHTTP(w, httptest.NewRequest("GET", "http://localhost:8080/metrics", nil))
+	assert.Equal(t, metricsContentType, w.Header().Get("Content-Type"))
+	assert.True(t, strings.Contains(w.Body.String(), `aah_http_requests_total{route="create_user",method="POST",status="2xx"} 1`))
+	assert.True(t, strings.Contains(w.Body.String(), "aah_panics_recovered_total 1"))
+}
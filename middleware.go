@@ -24,6 +24,7 @@ type (
 	Middleware struct {
 		next    MiddlewareFunc
 		further *Middleware
+		name    string
 	}
 )
 
@@ -87,6 +88,7 @@ func (mw *Middleware) Next(ctx *Context) {
 	}
 
 	if mw.next != nil {
+		defer traceStage(ctx, mw.name)()
 		mw.next(ctx, mw.further)
 	}
 }
@@ -108,13 +110,11 @@ func interceptorMiddleware(ctx *Context, m *Middleware) {
 		}
 
 		if finallyActionMethod := target.MethodByName(incpFinallyActionName + ctx.action.Name); finallyActionMethod.IsValid() {
-			log.Debugf("Calling interceptor: %s.%s", ctx.controller, incpFinallyActionName+ctx.action.Name)
-			finallyActionMethod.Call(emptyArg)
+			callInterceptor(ctx, finallyActionMethod, incpFinallyActionName+ctx.action.Name, emptyArg)
 		}
 
 		if finallyAction := target.MethodByName(incpFinallyActionName); finallyAction.IsValid() {
-			log.Debugf("Calling interceptor: %s.%s", ctx.controller, incpFinallyActionName)
-			finallyAction.Call(emptyArg)
+			callInterceptor(ctx, finallyAction, incpFinallyActionName, emptyArg)
 		}
 	}()
 
@@ -126,13 +126,11 @@ func interceptorMiddleware(ctx *Context, m *Middleware) {
 			}
 
 			if panicActionMethod := target.MethodByName(incpPanicActionName + ctx.action.Name); panicActionMethod.IsValid() {
-				log.Debugf("Calling interceptor: %s.%s", ctx.controller, incpPanicActionName+ctx.action.Name)
 				rv := append([]reflect.Value{}, reflect.ValueOf(r))
-				panicActionMethod.Call(rv)
+				callInterceptor(ctx, panicActionMethod, incpPanicActionName+ctx.action.Name, rv)
 			} else if panicAction := target.MethodByName(incpPanicActionName); panicAction.IsValid() {
-				log.Debugf("Calling interceptor: %s.%s", ctx.controller, incpPanicActionName)
 				rv := append([]reflect.Value{}, reflect.ValueOf(r))
-				panicAction.Call(rv)
+				callInterceptor(ctx, panicAction, incpPanicActionName, rv)
 			} else { // propagate it
 				panic(r)
 			}
@@ -141,15 +139,13 @@ func interceptorMiddleware(ctx *Context, m *Middleware) {
 
 	// Before action
 	if beforeAction := target.MethodByName(incpBeforeActionName); beforeAction.IsValid() {
-		log.Debugf("Calling interceptor: %s.%s", ctx.controller, incpBeforeActionName)
-		beforeAction.Call(emptyArg)
+		callInterceptor(ctx, beforeAction, incpBeforeActionName, emptyArg)
 	}
 
 	// Before action method
 	if !ctx.abort {
 		if beforeActionMethod := target.MethodByName(incpBeforeActionName + ctx.action.Name); beforeActionMethod.IsValid() {
-			log.Debugf("Calling interceptor: %s.%s", ctx.controller, incpBeforeActionName+ctx.action.Name)
-			beforeActionMethod.Call(emptyArg)
+			callInterceptor(ctx, beforeActionMethod, incpBeforeActionName+ctx.action.Name, emptyArg)
 		}
 	}
 
@@ -158,16 +154,14 @@ func interceptorMiddleware(ctx *Context, m *Middleware) {
 	// After action method
 	if !ctx.abort {
 		if afterActionMethod := target.MethodByName(incpAfterActionName + ctx.action.Name); afterActionMethod.IsValid() {
-			log.Debugf("Calling interceptor: %s.%s", ctx.controller, incpAfterActionName+ctx.action.Name)
-			afterActionMethod.Call(emptyArg)
+			callInterceptor(ctx, afterActionMethod, incpAfterActionName+ctx.action.Name, emptyArg)
 		}
 	}
 
 	// After action
 	if !ctx.abort {
 		if afterAction := target.MethodByName(incpAfterActionName); afterAction.IsValid() {
-			log.Debugf("Calling interceptor: %s.%s", ctx.controller, incpAfterActionName)
-			afterAction.Call(emptyArg)
+			callInterceptor(ctx, afterAction, incpAfterActionName, emptyArg)
 		}
 	}
 }
@@ -185,11 +179,42 @@ func actionMiddleware(ctx *Context, m *Middleware) {
 		return
 	}
 
+	// Response cache of routes.conf `cache`, it's looked up after the
+	// middlewares and `Before` interceptors, so authentication and
+	// authorization are not bypassed. Cached reply is written without
+	// calling the action.
+	if ctx.cacheTTL == 0 && checkCache(ctx, routeCacheTTL(ctx)) {
+		ctx.Abort()
+		return
+	}
+
 	actionArgs := make([]reflect.Value, len(ctx.action.Parameters))
+	for idx, param := range ctx.action.Parameters {
+		value, err := bindParameter(ctx, param)
+		if err != nil {
+			replyBindError(ctx, err)
+			return
+		}
+		actionArgs[idx] = value
+	}
 
-	// TODO Auto Binder for arguments
+	// Validate bound action parameters
+	if ctx.validationErrors = validateParameters(ctx, actionArgs); len(ctx.validationErrors) > 0 {
+		ctx.Log().Debugf("Validation errors on %s: %d", ctx.Req.Path, len(ctx.validationErrors))
+		ctx.AddViewArg(keyValidationErrors, ctx.validationErrors)
+		if isAutoRejectValidation(ctx) {
+			replyValidationErrors(ctx)
+			return
+		}
+	}
 
-	log.Debugf("Calling controller: %s.%s", ctx.controller, ctx.action.Name)
+	ctx.Log().Debugf("Calling controller: %s.%s", ctx.controller, ctx.action.Name)
+	// span name is built only if request is traced
+	var spanName string
+	if ctx.span != nil {
+		spanName = "action " + ctx.controller + "." + ctx.action.Name
+	}
+	defer pipelineStage(ctx, spanName, stageAction)()
 	if action.Type().IsVariadic() {
 		action.CallSlice(actionArgs)
 	} else {
@@ -202,6 +227,17 @@ func actionMiddleware(ctx *Context, m *Middleware) {
 // Unexported methods
 //___________________________________
 
+// callInterceptor method calls the controller interceptor with given args.
+func callInterceptor(ctx *Context, method reflect.Value, name string, args []reflect.Value) {
+	ctx.Log().Debugf("Calling interceptor: %s.%s", ctx.controller, name)
+	var spanName string
+	if ctx.span != nil {
+		spanName = "interceptor " + name
+	}
+	defer pipelineStage(ctx, spanName, stageInterceptors)()
+	method.Call(args)
+}
+
 func invalidateMwChain() {
 	mwChain = nil
 	cnt := len(mwStack)
@@ -209,6 +245,9 @@ func invalidateMwChain() {
 
 	for idx := 0; idx < cnt; idx++ {
 		mwChain[idx] = &Middleware{next: mwStack[idx]}
+		if mwStack[idx] != nil {
+			mwChain[idx].name = "middleware " + funcName(mwStack[idx])
+		}
 	}
 
 	for idx := cnt - 1; idx > 0; idx-- {
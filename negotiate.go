@@ -0,0 +1,225 @@
+// Copyright (c) Jeevanandam M. (https://github.com/jeevatkm)
+// go-aah/aah source code and usage is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package aah
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"aahframework.org/ahttp.v0"
+	"aahframework.org/essentials.v0"
+)
+
+var (
+	renderers     = make(map[string]*renderer)
+	rendererMimes []string
+)
+
+type (
+	// RenderFactory func type creates the Render for the given data, it's used
+	// by content negotiation `Reply().Negotiate`.
+	RenderFactory func(data interface{}) Render
+
+	// renderer holds the registered content type and its render factory.
+	renderer struct {
+		contentType string
+		factory     RenderFactory
+	}
+
+	// negotiateRender holds the data of `Reply().Negotiate` until the render
+	// is chosen as per request `Accept` header.
+	negotiateRender struct {
+		Data interface{}
+	}
+
+	// acceptSpec is media range of `Accept` header with quality value.
+	acceptSpec struct {
+		mime string
+		q    float64
+	}
+)
+
+//‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾
+// Global methods
+//___________________________________
+
+// AddRenderer method adds the given render factory for the content type into
+// renderer registry, which is used by content negotiation `Reply().Negotiate`.
+// Content type params such as `charset` are sent as-is in the reply.
+// Framework registers JSON, XML, Text, YAML, CSV and MessagePack by default.
+//		For Example:
+//
+//		aah.AddRenderer("application/vnd.ms-excel", func(data interface{}) aah.Render {
+//			return &ExcelRender{Data: data}
+//		})
+func AddRenderer(contentType string, factory RenderFactory) error {
+	mime := mimeOf(contentType)
+	if ess.IsStrEmpty(mime) || factory == nil {
+		return errors.New("renderer: content type or factory is nil")
+	}
+
+	if _, found := renderers[mime]; found {
+		return fmt.Errorf("renderer: content type '%s' is already added", mime)
+	}
+
+	renderers[mime] = &renderer{contentType: contentType, factory: factory}
+	rendererMimes = append(rendererMimes, mime)
+	return nil
+}
+
+//‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾
+// negotiateRender methods
+//___________________________________
+
+// Render method is implementation of Render interface, actual render is
+// chosen by content negotiation before writing the reply.
+func (n *negotiateRender) Render(w io.Writer) error {
+	return errors.New("renderer: content is not negotiated")
+}
+
+//‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾
+// Unexported methods
+//___________________________________
+
+// negotiateRenderer method chooses the render for the reply data from
+// renderer registry by request `Accept` header quality values. Reply is
+// `406 Not Acceptable` if none of the renderers matches.
+func (e *engine) negotiateRenderer(ctx *Context, nr *negotiateRender) {
+	reply := ctx.Reply()
+	reply.Hdr.Add(ahttp.HeaderVary, ahttp.HeaderAccept)
+
+	mime := negotiateMime(ctx.Req.Header.Get(ahttp.HeaderAccept), rendererOffers())
+	if ess.IsStrEmpty(mime) {
+		ctx.Log().Warnf("Not Acceptable on %s, Accept: %s", ctx.Req.Path, ctx.Req.Header.Get(ahttp.HeaderAccept))
+		handleError(ctx, &HTTPError{Code: http.StatusNotAcceptable})
+		return
+	}
+
+	r := renderers[mime]
+	reply.ContentType(r.contentType)
+	reply.Rdr = r.factory(nr.Data)
+}
+
+// rendererOffers method returns the registered renderer mimes, `render.default`
+// from aah.conf is preferred if it's registered.
+func rendererOffers() []string {
+	ct := defaultContentType()
+	if ct == nil {
+		return rendererMimes
+	}
+
+	if _, found := renderers[ct.Mime]; !found {
+		return rendererMimes
+	}
+
+	offers := []string{ct.Mime}
+	for _, mime := range rendererMimes {
+		if mime != ct.Mime {
+			offers = append(offers, mime)
+		}
+	}
+	return offers
+}
+
+// negotiateMime method returns the best matching offer for the given `Accept`
+// header value as per RFC 7231, 5.3.2. Most specific media range decides the
+// quality value of an offer, on tie offers order is preferred. It returns
+// empty string if none of the offers is acceptable.
+func negotiateMime(accept string, offers []string) string {
+	specs := parseAccept(accept)
+	bestMime, bestQ := "", 0.0
+	for _, offer := range offers {
+		q, specificity := 0.0, -1
+		for _, spec := range specs {
+			s := mediaRangeSpecificity(spec.mime, offer)
+			if s > specificity {
+				q, specificity = spec.q, s
+			}
+		}
+
+		if q > bestQ {
+			bestMime, bestQ = offer, q
+		}
+	}
+	return bestMime
+}
+
+// parseAccept method parses the `Accept` header value into media ranges with
+// quality value, empty value is treated as `*/*`.
+func parseAccept(accept string) []*acceptSpec {
+	if ess.IsStrEmpty(strings.TrimSpace(accept)) {
+		return []*acceptSpec{{mime: "*/*", q: 1}}
+	}
+
+	var specs []*acceptSpec
+	for _, part := range strings.Split(accept, ",") {
+		params := strings.Split(part, ";")
+		spec := &acceptSpec{mime: strings.ToLower(strings.TrimSpace(params[0])), q: 1}
+		if ess.IsStrEmpty(spec.mime) {
+			continue
+		}
+
+		for _, param := range params[1:] {
+			param = strings.TrimSpace(param)
+			if strings.HasPrefix(param, "q=") {
+				if q, err := strconv.ParseFloat(param[2:], 64); err == nil {
+					spec.q = q
+				}
+			}
+		}
+		specs = append(specs, spec)
+	}
+	return specs
+}
+
+// mediaRangeSpecificity method returns the specificity of media range match
+// for the mime, exact `2`, `type/*` `1`, `*/*` `0` otherwise `-1`.
+func mediaRangeSpecificity(mediaRange, mime string) int {
+	switch {
+	case mediaRange == mime:
+		return 2
+	case mediaRange == "*/*":
+		return 0
+	case strings.HasSuffix(mediaRange, "/*") &&
+		strings.HasPrefix(mime, mediaRange[:len(mediaRange)-1]):
+		return 1
+	default:
+		return -1
+	}
+}
+
+// mimeOf method returns the lowercase mime of the given content type
+// without params.
+func mimeOf(contentType string) string {
+	if idx := strings.IndexByte(contentType, ';'); idx > 0 {
+		contentType = contentType[:idx]
+	}
+	return strings.ToLower(strings.TrimSpace(contentType))
+}
+
+func init() {
+	_ = AddRenderer(ahttp.ContentTypeJSON.Raw(), func(data interface{}) Render {
+		return &JSON{Data: data}
+	})
+	_ = AddRenderer(ahttp.ContentTypeXML.Raw(), func(data interface{}) Render {
+		return &XML{Data: data}
+	})
+	_ = AddRenderer(ahttp.ContentTypePlainText.Raw(), func(data interface{}) Render {
+		return &Text{Format: "%v", Values: []interface{}{data}}
+	})
+	_ = AddRenderer(contentTypeYAML, func(data interface{}) Render {
+		return &YAML{Data: data}
+	})
+	_ = AddRenderer(contentTypeCSV, func(data interface{}) Render {
+		return &CSV{Data: data}
+	})
+	_ = AddRenderer(contentTypeMsgPack, func(data interface{}) Render {
+		return &MsgPack{Data: data}
+	})
+}
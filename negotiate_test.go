@@ -0,0 +1,119 @@
+// Copyright (c) Jeevanandam M. (https://github.com/jeevatkm)
+// go-aah/aah source code and usage is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package aah
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+
+	"aahframework.org/ahttp.v0"
+	"aahframework.org/config.v0"
+	"aahframework.org/test.v0/assert"
+)
+
+type testReportRender struct {
+	Data interface{}
+}
+
+func (r *testReportRender) Render(w io.Writer) error {
+	_, err := fmt.Fprintf(w, "name\n%v\n", r.Data.(Data)["name"])
+	return err
+}
+
+func TestNegotiateMime(t *testing.T) {
+	offers := []string{"application/json", "application/xml", "text/plain"}
+
+	testcases := []struct {
+		accept, expected string
+	}{
+		{"", "application/json"},
+		{"*/*", "application/json"},
+		{"application/xml", "application/xml"},
+		{"application/xml;q=0.9, application/json", "application/json"},
+		{"text/html, application/xml;q=0.9, */*;q=0.8", "application/xml"},
+		{"text/*, application/json;q=0.5", "text/plain"},
+		{"*/*, application/json;q=0", "application/xml"},
+		{"Application/XML", "application/xml"},
+		{"application/pdf", ""},
+		{"text/html, image/*;q=0.8", ""},
+	}
+
+	for _, tc := range testcases {
+		assert.Equal(t, tc.expected, negotiateMime(tc.accept, offers))
+	}
+}
+
+func TestNegotiateAddRenderer(t *testing.T) {
+	defer func() {
+		delete(renderers, "text/x-report")
+		rendererMimes = rendererMimes[:len(rendererMimes)-1]
+	}()
+
+	err := AddRenderer("text/x-report; charset=utf-8", func(data interface{}) Render {
+		return &testReportRender{Data: data}
+	})
+	assert.Nil(t, err)
+	assert.Equal(t, "text/x-report", rendererMimes[len(rendererMimes)-1])
+
+	err = AddRenderer("Text/X-Report", func(data interface{}) Render { return nil })
+	assert.Equal(t, "renderer: content type 'text/x-report' is already added", err.Error())
+
+	err = AddRenderer("", nil)
+	assert.Equal(t, "renderer: content type or factory is nil", err.Error())
+
+	assert.Equal(t, "application/json", mimeOf("Application/JSON; charset=utf-8"))
+}
+
+func TestNegotiateWriteReply(t *testing.T) {
+	appConfig, _ = config.ParseString("")
+	err := initSecurity(filepath.Join(getTestdataPath(), appConfigDir()), appConfig)
+	assert.FailNowOnError(t, err, "")
+	e := newEngine(appConfig)
+
+	_ = AddRenderer("text/x-report; charset=utf-8", func(data interface{}) Render {
+		return &testReportRender{Data: data}
+	})
+	defer func() {
+		delete(renderers, "text/x-report")
+		rendererMimes = rendererMimes[:len(rendererMimes)-1]
+	}()
+
+	reply := func(accept string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest("GET", "http://localhost:8080/users/1001", nil)
+		req.Header.Set(ahttp.HeaderAccept, accept)
+		w := httptest.NewRecorder()
+		ctx := e.prepareContext(w, req)
+		ctx.Reply().Negotiate(Data{"name": "aah"})
+		e.writeReply(ctx)
+		return w
+	}
+
+	w := reply("application/xml;q=0.5, application/json")
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, ahttp.ContentTypeJSON.Raw(), w.Header().Get(ahttp.HeaderContentType))
+	assert.Equal(t, ahttp.HeaderAccept, w.Header().Get(ahttp.HeaderVary))
+	assert.Equal(t, `{"name":"aah"}`, w.Body.String())
+
+	w = reply("text/x-report, application/json;q=0.9")
+	assert.Equal(t, "text/x-report; charset=utf-8", w.Header().Get(ahttp.HeaderContentType))
+	assert.Equal(t, "name\naah\n", w.Body.String())
+
+	w = reply("text/plain")
+	assert.Equal(t, "map[name:aah]", w.Body.String())
+
+	// render.default is preferred
+	appConfig.SetString("render.default", "xml")
+	w = reply("*/*")
+	assert.Equal(t, ahttp.ContentTypeXML.Raw(), w.Header().Get(ahttp.HeaderContentType))
+
+	// nothing matches
+	w = reply("application/pdf")
+	assert.Equal(t, http.StatusNotAcceptable, w.Code)
+	assert.Equal(t, "406 Not Acceptable", w.Body.String())
+}
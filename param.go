@@ -5,12 +5,14 @@
 package aah
 
 import (
+	"errors"
+	"io"
 	"io/ioutil"
 	"net/http"
+	"strings"
 
 	"aahframework.org/ahttp.v0"
 	ess "aahframework.org/essentials.v0"
-	"aahframework.org/log.v0"
 )
 
 const (
@@ -18,6 +20,16 @@ const (
 	keyOverrideLocale = "lang"
 )
 
+var errRequestEntityTooLarge = errors.New("request entity too large")
+
+// limitedBodyReader reads the request body upto remaining bytes, it
+// returns `errRequestEntityTooLarge` when body exceeds the limit.
+type limitedBodyReader struct {
+	rc        io.ReadCloser
+	remaining int64
+	exceeded  bool
+}
+
 //‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾
 // Params method
 //___________________________________
@@ -25,47 +37,38 @@ const (
 // parseRequestParams method parses the incoming HTTP request to collects request
 // parameters (Payload, Form, Query, Multi-part) stores into context. Request
 // params are made available in View via template functions.
-func (e *engine) parseRequestParams(ctx *Context) {
+//
+// Request body is limited to `max_body_size`, multipart request to
+// `multipart.max_body_size`. It returns
+// `errRequestEntityTooLarge` when exceeded. Request body is not parsed for
+// streaming body route `stream_body = true`, action reads it via `ctx.Body()`.
+func (e *engine) parseRequestParams(ctx *Context) error {
+	defer pipelineStage(ctx, "parseRequestParams", stageParams)()
+
 	req := ctx.Req.Raw
 
 	if ctx.Req.Method != ahttp.MethodGet {
 		contentType := ctx.Req.ContentType.Mime
-		log.Debugf("request content type: %s", contentType)
+		ctx.Log().Debugf("request content type: %s", contentType)
 
-		// TODO add support for content-type restriction and return 415 status for that
-		// TODO HTML sanitizer for Form and Multipart Form
-
-		switch contentType {
-		case ahttp.ContentTypeJSON.Mime, ahttp.ContentTypeXML.Mime:
-			if payloadBytes, err := ioutil.ReadAll(req.Body); err == nil {
-				ctx.Req.Payload = payloadBytes
-			} else {
-				log.Errorf("unable to read request body for '%s': %s", contentType, err)
-			}
-		case ahttp.ContentTypeForm.Mime:
-			if err := req.ParseForm(); err == nil {
-				ctx.Req.Params.Form = req.Form
-			} else {
-				log.Errorf("unable to parse form: %s", err)
+		var body *limitedBodyReader
+		if maxBodyBytes := requestMaxBodyBytes(ctx); maxBodyBytes > 0 && req.Body != nil {
+			if req.ContentLength > maxBodyBytes {
+				return errRequestEntityTooLarge
 			}
-		case ahttp.ContentTypeMultipartForm.Mime:
-			if err := req.ParseMultipartForm(appMultipartMaxMemory); err == nil {
-				ctx.Req.Params.Form = req.MultipartForm.Value
-				ctx.Req.Params.File = req.MultipartForm.File
-			} else {
-				log.Errorf("unable to parse multipart form: %s", err)
-			}
-		} // switch end
-
-		// clean up
-		defer func(r *http.Request) {
-			if r.MultipartForm != nil {
-				log.Debug("multipart form file clean up")
-				if err := r.MultipartForm.RemoveAll(); err != nil {
-					log.Error(err)
-				}
+
+			body = &limitedBodyReader{rc: req.Body, remaining: maxBodyBytes}
+			req.Body = body
+		}
+
+		if routeBoolDefault(ctx, "stream_body", false) {
+			ctx.Log().Debugf("Streaming body route, request body is not parsed: %s", ctx.Req.Path)
+		} else {
+			e.parseRequestBody(ctx)
+			if body != nil && body.exceeded {
+				return errRequestEntityTooLarge
 			}
-		}(req)
+		}
 	}
 
 	// i18n option override by Query parameter `lang`
@@ -75,6 +78,116 @@ func (e *engine) parseRequestParams(ctx *Context) {
 
 	// All the request parameters made available to templates via funcs.
 	ctx.AddViewArg(keyRequestParams, ctx.Req.Params)
+
+	return nil
+}
+
+// parseRequestBody method parses the request body based on request
+// content type.
+func (e *engine) parseRequestBody(ctx *Context) {
+	req := ctx.Req.Raw
+	contentType := ctx.Req.ContentType.Mime
+
+	switch {
+	case isJSONContentType(contentType), isXMLContentType(contentType):
+		if payloadBytes, err := ioutil.ReadAll(req.Body); err == nil {
+			ctx.Req.Payload = payloadBytes
+		} else {
+			ctx.Log().Errorf("unable to read request body for '%s': %s", contentType, err)
+		}
+	case contentType == ahttp.ContentTypeForm.Mime:
+		if err := req.ParseForm(); err == nil {
+			ctx.Req.Params.Form = req.Form
+		} else {
+			ctx.Log().Errorf("unable to parse form: %s", err)
+		}
+	case contentType == ahttp.ContentTypeMultipartForm.Mime:
+		if err := req.ParseMultipartForm(appMultipartMaxMemory); err == nil {
+			ctx.Req.Params.Form = req.MultipartForm.Value
+			ctx.Req.Params.File = req.MultipartForm.File
+		} else {
+			ctx.Log().Errorf("unable to parse multipart form: %s", err)
+		}
+	} // switch end
+
+	// HTML sanitizer for Form and Multipart Form
+	sanitizeFormParams(ctx)
+}
+
+// cleanupMultipartForm method removes the multipart form temporary files,
+// it's called once the request is completed, since action may read the
+// uploaded files.
+func cleanupMultipartForm(ctx *Context) {
+	if r := ctx.Req.Raw; r != nil && r.MultipartForm != nil {
+		ctx.Log().Debug("multipart form file clean up")
+		if err := r.MultipartForm.RemoveAll(); err != nil {
+			ctx.Log().Error(err)
+		}
+	}
+}
+
+// checkContentType method verifies the request Content-Type against allowed
+// content types of the route `content_types` from routes.conf otherwise
+// `request.content_types` from aah.conf. Empty list allows all.
+//
+// It replies HTTP Unsupported Media Type (Status 415) with `Accept` header
+// and returns false if request Content-Type is not allowed. Request without
+// body is not verified.
+func (e *engine) checkContentType(ctx *Context) bool {
+	if !isBodyAllowed(ctx.Req.Method) {
+		return true
+	}
+
+	if r := ctx.Req.Raw; r.ContentLength == 0 && len(r.TransferEncoding) == 0 {
+		return true
+	}
+
+	allowed := routeStringList(ctx, "content_types", e.contentTypes)
+	if len(allowed) == 0 {
+		return true
+	}
+
+	contentType := ctx.Req.ContentType.Mime
+	for _, mediaType := range allowed {
+		if isMediaTypeMatch(mediaType, contentType) {
+			return true
+		}
+	}
+
+	ctx.Log().Warnf("Unsupported Content-Type '%s' on %s, allowed: %s", contentType, ctx.Req.Path, allowed)
+	ctx.Reply().Header(ahttp.HeaderAccept, strings.Join(allowed, ", "))
+	handleError(ctx, &HTTPError{Code: http.StatusUnsupportedMediaType})
+	return false
+}
+
+//‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾
+// limitedBodyReader methods
+//___________________________________
+
+// Read method is implementation of io.Reader interface.
+func (l *limitedBodyReader) Read(p []byte) (int, error) {
+	if l.exceeded {
+		return 0, errRequestEntityTooLarge
+	}
+
+	// read one byte more than remaining to detect the exceeded body
+	if int64(len(p)) > l.remaining+1 {
+		p = p[:l.remaining+1]
+	}
+
+	n, err := l.rc.Read(p)
+	if int64(n) <= l.remaining {
+		l.remaining -= int64(n)
+		return n, err
+	}
+
+	n, l.remaining, l.exceeded = int(l.remaining), 0, true
+	return n, errRequestEntityTooLarge
+}
+
+// Close method is implementation of io.Closer interface.
+func (l *limitedBodyReader) Close() error {
+	return l.rc.Close()
 }
 
 //‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾
@@ -98,3 +211,66 @@ func tmplQueryParam(viewArgs map[string]interface{}, key string) interface{} {
 	params := viewArgs[keyRequestParams].(*ahttp.Params)
 	return sanatizeValue(params.QueryValue(key))
 }
+
+//‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾
+// Unexported methods
+//___________________________________
+
+// requestMaxBodyBytes method returns the route `max_body_size` from
+// routes.conf otherwise `request.max_body_size` from aah.conf. Multipart
+// request gets `request.multipart.max_body_size`, since file uploads are
+// larger than the regular request body.
+func requestMaxBodyBytes(ctx *Context) int64 {
+	if size := routeStringDefault(ctx, "max_body_size", ""); !ess.IsStrEmpty(size) {
+		if maxBodyBytes, err := ess.StrToBytes(size); err == nil {
+			return maxBodyBytes
+		}
+		ctx.Log().Errorf("route '%s': 'max_body_size' value is not a valid size unit", ctx.route.Name)
+	}
+
+	if ctx.Req.ContentType.Mime == ahttp.ContentTypeMultipartForm.Mime {
+		return appMultipartBodyBytes
+	}
+	return appMaxBodyBytes
+}
+
+// isBodyAllowed method returns true if request body is processed for the
+// given HTTP method.
+func isBodyAllowed(method string) bool {
+	return !(method == ahttp.MethodGet || method == ahttp.MethodHead ||
+		method == ahttp.MethodOptions)
+}
+
+// isJSONContentType method returns true for `application/json` and vendor
+// type `application/*+json`. For e.g.: `application/vnd.api+json`.
+func isJSONContentType(mime string) bool {
+	return mime == ahttp.ContentTypeJSON.Mime ||
+		(strings.HasPrefix(mime, "application/") && strings.HasSuffix(mime, "+json"))
+}
+
+// isXMLContentType method returns true for `application/xml` and vendor
+// type `application/*+xml`. For e.g.: `application/atom+xml`.
+func isXMLContentType(mime string) bool {
+	return mime == ahttp.ContentTypeXML.Mime ||
+		(strings.HasPrefix(mime, "application/") && strings.HasSuffix(mime, "+xml"))
+}
+
+// isMediaTypeMatch method returns true if given mime matches with media type
+// pattern. Pattern can be exact `application/json`, type wildcard
+// `application/*`, suffix wildcard `application/*+json` or `*/*`.
+func isMediaTypeMatch(pattern, mime string) bool {
+	pattern = strings.ToLower(strings.TrimSpace(pattern))
+	mime = strings.ToLower(mime)
+	if pattern == "*/*" || pattern == mime {
+		return true
+	}
+
+	idx := strings.Index(pattern, "/*")
+	if idx == -1 {
+		return false
+	}
+
+	prefix, suffix := pattern[:idx+1], pattern[idx+2:]
+	return strings.HasPrefix(mime, prefix) && strings.HasSuffix(mime, suffix) &&
+		len(mime) > len(prefix)+len(suffix)
+}
@@ -5,6 +5,9 @@
 package aah
 
 import (
+	"bytes"
+	"io/ioutil"
+	"mime/multipart"
 	"net/http"
 	"net/http/httptest"
 	"net/url"
@@ -12,7 +15,9 @@ import (
 	"testing"
 
 	"aahframework.org/ahttp.v0"
+	"aahframework.org/config.v0"
 	"aahframework.org/essentials.v0"
+	"aahframework.org/router.v0"
 	"aahframework.org/test.v0/assert"
 )
 
@@ -62,3 +67,191 @@ func TestParamParse(t *testing.T) {
 	assert.Equal(t, "CA", ctx1.Req.Locale.Region)
 	assert.Equal(t, "en-CA", ctx1.Req.Locale.String())
 }
+
+func TestParamMediaTypeMatch(t *testing.T) {
+	assert.True(t, isMediaTypeMatch("application/json", "application/json"))
+	assert.True(t, isMediaTypeMatch("Application/JSON", "application/json"))
+	assert.True(t, isMediaTypeMatch("application/*", "application/xml"))
+	assert.True(t, isMediaTypeMatch("application/*+json", "application/vnd.api+json"))
+	assert.True(t, isMediaTypeMatch("*/*", "text/plain"))
+	assert.False(t, isMediaTypeMatch("application/*+json", "application/+json"))
+	assert.False(t, isMediaTypeMatch("application/*+json", "application/xml"))
+	assert.False(t, isMediaTypeMatch("application/json", "text/plain"))
+	assert.False(t, isMediaTypeMatch("application/*", "text/html"))
+
+	assert.True(t, isJSONContentType("application/json"))
+	assert.True(t, isJSONContentType("application/problem+json"))
+	assert.False(t, isJSONContentType("text/json+xml"))
+	assert.True(t, isXMLContentType("application/xml"))
+	assert.True(t, isXMLContentType("application/atom+xml"))
+	assert.False(t, isXMLContentType("application/json"))
+}
+
+func TestParamCheckContentType(t *testing.T) {
+	e := &engine{contentTypes: []string{"application/json", "application/*+xml"}}
+
+	newCtx := func(method, contentType string) *Context {
+		req := httptest.NewRequest(method, "http://localhost:8080/users", strings.NewReader("name=aah"))
+		req.Header.Set(ahttp.HeaderContentType, contentType)
+		req.Header.Set(ahttp.HeaderAccept, ahttp.ContentTypePlainText.Raw())
+		return &Context{Req: ahttp.ParseRequest(req, &ahttp.Request{}), reply: NewReply()}
+	}
+
+	ctx := newCtx("POST", "application/json; charset=utf-8")
+	assert.True(t, e.checkContentType(ctx))
+
+	ctx = newCtx("PUT", "application/atom+xml")
+	assert.True(t, e.checkContentType(ctx))
+
+	ctx = newCtx("GET", "text/plain")
+	assert.True(t, e.checkContentType(ctx))
+
+	ctx = newCtx("POST", ahttp.ContentTypeForm.Raw())
+	assert.False(t, e.checkContentType(ctx))
+	assert.Equal(t, http.StatusUnsupportedMediaType, ctx.Reply().Code)
+	assert.Equal(t, "application/json, application/*+xml", ctx.Reply().Hdr.Get(ahttp.HeaderAccept))
+	assert.Equal(t, "415 Unsupported Media Type", ctx.Reply().Rdr.(*Text).Format)
+
+	// request without body
+	ctx = newCtx("POST", ahttp.ContentTypeForm.Raw())
+	ctx.Req.Raw.ContentLength = 0
+	assert.True(t, e.checkContentType(ctx))
+
+	// chunked body
+	ctx.Req.Raw.ContentLength, ctx.Req.Raw.TransferEncoding = -1, []string{"chunked"}
+	assert.False(t, e.checkContentType(ctx))
+
+	// empty list allows all
+	e.contentTypes = nil
+	ctx = newCtx("POST", ahttp.ContentTypeForm.Raw())
+	assert.True(t, e.checkContentType(ctx))
+}
+
+func TestParamRequestBodyLimit(t *testing.T) {
+	appConfig, _ = config.ParseString("")
+	appRoutesConfig, _ = config.ParseString("")
+	appRoutesConfig.SetBool("domains.localhost.routes.upload.stream_body", true)
+	appRoutesConfig.SetString("domains.localhost.routes.upload.max_body_size", "1kb")
+	appRoutesConfig.SetString("domains.localhost.routes.files.max_body_size", "4kb")
+	appRouteConfigPaths = map[string]string{
+		routeConfigKey("localhost", "upload"): "domains.localhost.routes.upload",
+		routeConfigKey("localhost", "files"):  "domains.localhost.routes.files",
+	}
+	appMaxBodyBytes, appMultipartBodyBytes = 16, 1024
+	defer func() {
+		appRoutesConfig, appRouteConfigPaths = nil, nil
+		appMaxBodyBytes, appMultipartBodyBytes = 0, 0
+	}()
+
+	newCtx := func(body string, contentLength int64) *Context {
+		req := httptest.NewRequest("POST", "http://localhost:8080/users", strings.NewReader(body))
+		req.Header.Set(ahttp.HeaderContentType, ahttp.ContentTypeJSON.Raw())
+		req.ContentLength = contentLength
+		return &Context{
+			Req:      ahttp.ParseRequest(req, &ahttp.Request{}),
+			viewArgs: make(map[string]interface{}),
+		}
+	}
+
+	e := &engine{}
+	body := `{"name":"John"}`
+	ctx := newCtx(body, int64(len(body)))
+	assert.Nil(t, e.parseRequestParams(ctx))
+	assert.Equal(t, body, string(ctx.Req.Payload))
+
+	// exceeds by content length
+	body = `{"name":"John","age":28}`
+	ctx = newCtx(body, int64(len(body)))
+	assert.Equal(t, errRequestEntityTooLarge, e.parseRequestParams(ctx))
+
+	// exceeds while reading, unknown content length
+	ctx = newCtx(body, -1)
+	assert.Equal(t, errRequestEntityTooLarge, e.parseRequestParams(ctx))
+	assert.Nil(t, ctx.Req.Payload)
+
+	// multipart request is limited by `request.multipart.max_body_size`
+	newMultipartCtx := func(value string) *Context {
+		mbody := &bytes.Buffer{}
+		mw := multipart.NewWriter(mbody)
+		_ = mw.WriteField("name", value)
+		_ = mw.Close()
+		req := httptest.NewRequest("POST", "http://localhost:8080/files", mbody)
+		req.Header.Set(ahttp.HeaderContentType, mw.FormDataContentType())
+		return &Context{Req: ahttp.ParseRequest(req, &ahttp.Request{}), viewArgs: make(map[string]interface{})}
+	}
+	ctx = newMultipartCtx("aah web framework")
+	assert.Nil(t, e.parseRequestParams(ctx))
+	assert.Equal(t, "aah web framework", ctx.Req.Params.Form.Get("name"))
+	cleanupMultipartForm(ctx)
+
+	ctx = newMultipartCtx(strings.Repeat("a", 2048))
+	assert.Equal(t, errRequestEntityTooLarge, e.parseRequestParams(ctx))
+
+	// route `max_body_size` raises it
+	ctx = newMultipartCtx(strings.Repeat("a", 2048))
+	ctx.domain = &router.Domain{Host: "localhost"}
+	ctx.route = &router.Route{Name: "files"}
+	assert.Nil(t, e.parseRequestParams(ctx))
+	assert.Equal(t, 2048, len(ctx.Req.Params.Form.Get("name")))
+	cleanupMultipartForm(ctx)
+
+	// streaming body route
+	ctx = newCtx(body, -1)
+	ctx.domain = &router.Domain{Host: "localhost"}
+	ctx.route = &router.Route{Name: "upload"}
+	assert.Nil(t, e.parseRequestParams(ctx))
+	assert.Nil(t, ctx.Req.Payload)
+	b, err := ioutil.ReadAll(ctx.Body())
+	assert.Nil(t, err)
+	assert.Equal(t, body, string(b))
+}
+
+func TestParamMultipartCleanup(t *testing.T) {
+	appConfig, _ = config.ParseString("")
+	e := newEngine(appConfig)
+	oldMaxMemory := appMultipartMaxMemory
+	appMultipartMaxMemory = 8 // uploaded file is spooled to disk
+	defer func() { appMultipartMaxMemory = oldMaxMemory }()
+
+	body := &bytes.Buffer{}
+	mw := multipart.NewWriter(body)
+	fw, _ := mw.CreateFormFile("avatar", "avatar.png")
+	_, _ = fw.Write([]byte("aah framework avatar image content"))
+	_ = mw.Close()
+
+	req := httptest.NewRequest("POST", "http://localhost:8080/users/avatar", body)
+	req.Header.Set(ahttp.HeaderContentType, mw.FormDataContentType())
+	ctx := e.prepareContext(httptest.NewRecorder(), req)
+	assert.Nil(t, e.parseRequestParams(ctx))
+
+	// uploaded file is available for the action
+	fh := ctx.Req.Params.File["avatar"][0]
+	f, err := fh.Open()
+	assert.FailNowOnError(t, err, "")
+	b, _ := ioutil.ReadAll(f)
+	_ = f.Close()
+	assert.Equal(t, "aah framework avatar image content", string(b))
+
+	// removed once the request is completed
+	e.putContext(ctx)
+	_, err = fh.Open()
+	assert.NotNil(t, err)
+}
+
+func TestParamLimitedBodyReader(t *testing.T) {
+	r := &limitedBodyReader{rc: ioutil.NopCloser(strings.NewReader("aah framework")), remaining: 3}
+	b, err := ioutil.ReadAll(r)
+	assert.Equal(t, errRequestEntityTooLarge, err)
+	assert.Equal(t, "aah", string(b))
+	assert.True(t, r.exceeded)
+
+	_, err = r.Read(make([]byte, 4))
+	assert.Equal(t, errRequestEntityTooLarge, err)
+	assert.Nil(t, r.Close())
+
+	r = &limitedBodyReader{rc: ioutil.NopCloser(strings.NewReader("aah")), remaining: 3}
+	b, err = ioutil.ReadAll(r)
+	assert.Nil(t, err)
+	assert.Equal(t, "aah", string(b))
+	assert.False(t, r.exceeded)
+}
@@ -5,6 +5,9 @@
 package aah
 
 import (
+	"bytes"
+	"encoding"
+	"encoding/csv"
 	"encoding/json"
 	"encoding/xml"
 	"errors"
@@ -13,8 +16,19 @@ import (
 	"io"
 	"os"
 	"path/filepath"
+	"reflect"
+	"strings"
+	"time"
 
 	"aahframework.org/essentials.v0"
+	"github.com/vmihailenco/msgpack"
+	"gopkg.in/yaml.v2"
+)
+
+const (
+	contentTypeYAML    = "application/yaml; charset=utf-8"
+	contentTypeCSV     = "text/csv; charset=utf-8"
+	contentTypeMsgPack = "application/msgpack"
 )
 
 type (
@@ -32,11 +46,13 @@ type (
 		Values []interface{}
 	}
 
-	// JSON renders the response JSON content.
+	// JSON renders the response JSON content. Options defaults to
+	// application JSON options from aah.conf if nil.
 	JSON struct {
 		IsJSONP  bool
 		Callback string
 		Data     interface{}
+		Options  *JSONOptions
 	}
 
 	// XML renders the response XML content.
@@ -44,10 +60,38 @@ type (
 		Data interface{}
 	}
 
+	// YAML renders the response YAML content.
+	YAML struct {
+		Data interface{}
+	}
+
+	// CSV renders the response CSV content from `[][]string` or slice of
+	// structs. For structs, header row is composed from field tag `csv`
+	// otherwise field name, tag value `-` skips the field. It's streamed on
+	// the wire without buffering.
+	CSV struct {
+		Data interface{}
+	}
+
+	// MsgPack renders the response MessagePack content.
+	MsgPack struct {
+		Data interface{}
+	}
+
+	// Streamer interface is implemented by the renders which can write the
+	// response body directly on the wire without buffering in-memory, it's
+	// applicable only if `render.stream.enable` is true in aah.conf.
+	Streamer interface {
+		IsStreamable() bool
+	}
+
 	// Binary renders given path or io.Reader into response and closes the file.
+	// Seekable marks the reader to be served with HTTP Range and conditional
+	// requests support, reader must implement `io.ReadSeeker`.
 	Binary struct {
-		Path   string
-		Reader io.Reader
+		Path     string
+		Reader   io.Reader
+		Seekable bool
 	}
 
 	// HTML renders the given HTML into response with given model data.
@@ -57,6 +101,17 @@ type (
 		Filename string
 		ViewArgs Data
 	}
+
+	// Problem renders the problem details JSON content as per RFC 7807.
+	// Extensions are rendered as top-level members of problem details.
+	Problem struct {
+		Type       string
+		Title      string
+		Status     int
+		Detail     string
+		Instance   string
+		Extensions Data
+	}
 )
 
 //‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾
@@ -80,17 +135,12 @@ func (t *Text) Render(w io.Writer) error {
 
 // Render method writes JSON into HTTP response.
 func (j *JSON) Render(w io.Writer) error {
-	var (
-		bytes []byte
-		err   error
-	)
-
-	if appConfig.BoolDefault("render.pretty", false) {
-		bytes, err = json.MarshalIndent(j.Data, "", "    ")
-	} else {
-		bytes, err = json.Marshal(j.Data)
+	opts := j.Options
+	if opts == nil {
+		opts = appJSONOptions
 	}
 
+	bytes, err := encodeJSON(j.Data, opts)
 	if err != nil {
 		return err
 	}
@@ -99,6 +149,10 @@ func (j *JSON) Render(w io.Writer) error {
 		if _, err = w.Write([]byte(j.Callback + "(")); err != nil {
 			return err
 		}
+	} else if !ess.IsStrEmpty(opts.SecurePrefix) {
+		if _, err = io.WriteString(w, opts.SecurePrefix); err != nil {
+			return err
+		}
 	}
 
 	if _, err = w.Write(bytes); err != nil {
@@ -114,6 +168,46 @@ func (j *JSON) Render(w io.Writer) error {
 	return nil
 }
 
+//‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾
+// Problem Render methods
+//___________________________________
+
+// Render method writes problem details JSON into HTTP response.
+func (p *Problem) Render(w io.Writer) error {
+	return (&JSON{Data: p, Options: unprefixedJSONOptions()}).Render(w)
+}
+
+// MarshalJSON method is implementation of json.Marshaler interface. It
+// marshals the problem details members along with extension members.
+func (p *Problem) MarshalJSON() ([]byte, error) {
+	members := make(map[string]interface{}, len(p.Extensions)+5)
+	for k, v := range p.Extensions {
+		members[k] = v
+	}
+
+	if !ess.IsStrEmpty(p.Type) {
+		members["type"] = p.Type
+	}
+
+	if !ess.IsStrEmpty(p.Title) {
+		members["title"] = p.Title
+	}
+
+	if p.Status > 0 {
+		members["status"] = p.Status
+	}
+
+	if !ess.IsStrEmpty(p.Detail) {
+		members["detail"] = p.Detail
+	}
+
+	if !ess.IsStrEmpty(p.Instance) {
+		members["instance"] = p.Instance
+	}
+
+	return json.Marshal(members)
+}
+
 //‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾
 // XML Render methods
 //___________________________________
@@ -142,6 +236,87 @@ func (x *XML) Render(w io.Writer) error {
 	return nil
 }
 
+//‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾
+// YAML Render methods
+//___________________________________
+
+// Render method writes YAML into HTTP response.
+func (y *YAML) Render(w io.Writer) error {
+	bytes, err := yaml.Marshal(y.Data)
+	if err != nil {
+		return err
+	}
+
+	_, err = w.Write(bytes)
+	return err
+}
+
+//‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾
+// CSV Render methods
+//___________________________________
+
+// Render method writes CSV into HTTP response, rows are written as it
+// iterates the data.
+func (c *CSV) Render(w io.Writer) error {
+	cw := csv.NewWriter(w)
+	if records, ok := c.Data.([][]string); ok {
+		return writeCSVRecords(cw, records)
+	}
+
+	rv := reflect.Indirect(reflect.ValueOf(c.Data))
+	if !(rv.Kind() == reflect.Slice || rv.Kind() == reflect.Array) {
+		return fmt.Errorf("csv: unsupported data type '%T'", c.Data)
+	}
+
+	elemType := rv.Type().Elem()
+	if elemType.Kind() == reflect.Ptr {
+		elemType = elemType.Elem()
+	}
+
+	if elemType.Kind() != reflect.Struct {
+		return fmt.Errorf("csv: unsupported data type '%T'", c.Data)
+	}
+
+	header, fields := csvFields(elemType)
+	if err := cw.Write(header); err != nil {
+		return err
+	}
+
+	record := make([]string, len(fields))
+	for i := 0; i < rv.Len(); i++ {
+		ev := reflect.Indirect(rv.Index(i))
+		for idx, field := range fields {
+			if ev.IsValid() {
+				record[idx] = csvValue(ev.Field(field))
+			} else {
+				record[idx] = ""
+			}
+		}
+
+		if err := cw.Write(record); err != nil {
+			return err
+		}
+	}
+
+	cw.Flush()
+	return cw.Error()
+}
+
+// IsStreamable method returns true, CSV rows are written on the wire as
+// it iterates the data.
+func (c *CSV) IsStreamable() bool {
+	return true
+}
+
+//‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾
+// MsgPack Render methods
+//___________________________________
+
+// Render method writes MessagePack into HTTP response.
+func (m *MsgPack) Render(w io.Writer) error {
+	return msgpack.NewEncoder(w).Encode(m.Data)
+}
+
 //‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾
 // File and Reader Render methods
 //___________________________________
@@ -154,27 +329,90 @@ func (f *Binary) Render(w io.Writer) error {
 		return err
 	}
 
+	file, _, err := f.openFile()
+	if err != nil {
+		return err
+	}
+	defer ess.CloseQuietly(file)
+
+	_, err = io.Copy(w, file)
+	return err
+}
+
+// IsStreamable method returns true for file and reader, except in-memory
+// bytes which is buffered as usual.
+func (f *Binary) IsStreamable() bool {
+	switch f.Reader.(type) {
+	case *bytes.Reader, *bytes.Buffer, *strings.Reader:
+		return false
+	}
+	return true
+}
+
+// IsSeekable method returns true if it's a file or reader is marked as
+// seekable, framework serves it with HTTP Range and conditional requests.
+func (f *Binary) IsSeekable() bool {
+	if f.Reader == nil {
+		return !ess.IsStrEmpty(f.Path)
+	}
+	if !f.Seekable {
+		return false
+	}
+	_, ok := f.Reader.(io.ReadSeeker)
+	return ok
+}
+
+// content method returns the seekable content, name and modification time
+// of the file or reader. Name and modification time are known only for
+// file or reader has `Stat` method. For e.g.: *os.File
+func (f *Binary) content() (io.ReadSeeker, string, time.Time, error) {
+	if f.Reader == nil {
+		file, fi, err := f.openFile()
+		if err != nil {
+			return nil, "", time.Time{}, err
+		}
+		return file, fi.Name(), fi.ModTime(), nil
+	}
+
+	content, ok := f.Reader.(io.ReadSeeker)
+	if !ok {
+		return nil, "", time.Time{}, errors.New("reader is not seekable")
+	}
+
+	if statter, ok := f.Reader.(interface {
+		Stat() (os.FileInfo, error)
+	}); ok {
+		if fi, err := statter.Stat(); err == nil && fi.Mode().IsRegular() {
+			return content, fi.Name(), fi.ModTime(), nil
+		}
+	}
+	return content, "", time.Time{}, nil
+}
+
+// openFile method opens the file from given path, relative path is
+// resolved from application `static` directory.
+func (f *Binary) openFile() (*os.File, os.FileInfo, error) {
 	if !filepath.IsAbs(f.Path) {
 		f.Path = filepath.Join(AppBaseDir(), "static", f.Path)
 	}
 
 	file, err := os.Open(f.Path)
 	if err != nil {
-		return err
+		return nil, nil, err
 	}
-	defer ess.CloseQuietly(file)
 
 	fi, err := file.Stat()
 	if err != nil {
-		return err
+		ess.CloseQuietly(file)
+		return nil, nil, err
 	}
 
 	if fi.IsDir() {
-		return fmt.Errorf("'%s' is a directory", f.Path)
+		ess.CloseQuietly(file)
+		return nil, nil, fmt.Errorf("'%s' is a directory", f.Path)
 	}
 
-	_, err = io.Copy(w, file)
-	return err
+	return file, fi, nil
 }
 
 //‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾
@@ -193,3 +431,63 @@ func (h *HTML) Render(w io.Writer) error {
 
 	return h.Template.ExecuteTemplate(w, h.Layout, h.ViewArgs)
 }
+
+//‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾
+// Unexported methods
+//___________________________________
+
+func writeCSVRecords(cw *csv.Writer, records [][]string) error {
+	if err := cw.WriteAll(records); err != nil {
+		return err
+	}
+	return cw.Error()
+}
+
+// csvFields method returns the header row and exported field indexes of
+// the given struct type.
+func csvFields(typ reflect.Type) ([]string, []int) {
+	var (
+		header []string
+		fields []int
+	)
+
+	for i := 0; i < typ.NumField(); i++ {
+		f := typ.Field(i)
+		if !ess.IsStrEmpty(f.PkgPath) { // unexported
+			continue
+		}
+
+		name := f.Tag.Get("csv")
+		if name == "-" {
+			continue
+		}
+
+		if ess.IsStrEmpty(name) {
+			name = f.Name
+		}
+
+		header = append(header, name)
+		fields = append(fields, i)
+	}
+	return header, fields
+}
+
+// csvValue method returns the string value of the field, it uses
+// `encoding.TextMarshaler` if implemented by value or pointer receiver.
+func csvValue(v reflect.Value) string {
+	if v.Kind() == reflect.Ptr {
+		if v.IsNil() {
+			return ""
+		}
+		v = v.Elem()
+	}
+
+	ptr := reflect.New(v.Type())
+	ptr.Elem().Set(v)
+	if tm, ok := ptr.Interface().(encoding.TextMarshaler); ok {
+		if b, err := tm.MarshalText(); err == nil {
+			return string(b)
+		}
+	}
+	return fmt.Sprint(v.Interface())
+}
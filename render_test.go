@@ -6,15 +6,19 @@ package aah
 
 import (
 	"bytes"
+	"fmt"
 	"html/template"
+	"io/ioutil"
 	"os"
 	"path/filepath"
 	"strings"
 	"testing"
+	"time"
 
 	"aahframework.org/config.v0"
 	"aahframework.org/essentials.v0"
 	"aahframework.org/test.v0/assert"
+	"github.com/vmihailenco/msgpack"
 )
 
 func TestRenderText(t *testing.T) {
@@ -39,6 +43,7 @@ func TestRenderText(t *testing.T) {
 func TestRenderJSON(t *testing.T) {
 	buf := &bytes.Buffer{}
 	appConfig = getRenderCfg()
+	assert.Nil(t, initJSONOptions(appConfig))
 
 	data := struct {
 		Name    string
@@ -61,6 +66,7 @@ func TestRenderJSON(t *testing.T) {
 
 	buf.Reset()
 	appConfig.SetBool("render.pretty", false)
+	assert.Nil(t, initJSONOptions(appConfig))
 
 	err = json1.Render(buf)
 	assert.FailOnError(t, err, "")
@@ -71,6 +77,7 @@ func TestRenderJSON(t *testing.T) {
 func TestRenderJSONP(t *testing.T) {
 	buf := &bytes.Buffer{}
 	appConfig = getRenderCfg()
+	assert.Nil(t, initJSONOptions(appConfig))
 
 	data := struct {
 		Name    string
@@ -93,6 +100,7 @@ func TestRenderJSONP(t *testing.T) {
 
 	buf.Reset()
 	appConfig.SetBool("render.pretty", false)
+	assert.Nil(t, initJSONOptions(appConfig))
 
 	err = json1.Render(buf)
 	assert.FailOnError(t, err, "")
@@ -100,6 +108,33 @@ func TestRenderJSONP(t *testing.T) {
 		buf.String())
 }
 
+func TestRenderProblem(t *testing.T) {
+	buf := &bytes.Buffer{}
+	appConfig, _ = config.ParseString("")
+
+	problem := &Problem{
+		Type:     "https://example.com/probs/out-of-credit",
+		Title:    "You do not have enough credit.",
+		Status:   403,
+		Detail:   "Your current balance is 30, but that costs 50.",
+		Instance: "/account/12345/msgs/abc",
+		Extensions: Data{
+			"balance": 30,
+			"status":  "overridden by problem status",
+		},
+	}
+	err := problem.Render(buf)
+	assert.FailOnError(t, err, "")
+	assert.Equal(t, `{"balance":30,"detail":"Your current balance is 30, but that costs 50.",`+
+		`"instance":"/account/12345/msgs/abc","status":403,"title":"You do not have enough credit.",`+
+		`"type":"https://example.com/probs/out-of-credit"}`, buf.String())
+
+	buf.Reset()
+	err = (&Problem{Title: "Not Found", Status: 404}).Render(buf)
+	assert.FailOnError(t, err, "")
+	assert.Equal(t, `{"status":404,"title":"Not Found"}`, buf.String())
+}
+
 func TestRenderXML(t *testing.T) {
 	buf := &bytes.Buffer{}
 	appConfig = getRenderCfg()
@@ -135,6 +170,100 @@ func TestRenderXML(t *testing.T) {
 		buf.String())
 }
 
+func TestRenderYAML(t *testing.T) {
+	buf := &bytes.Buffer{}
+
+	type Sample struct {
+		Name    string   `yaml:"name"`
+		Age     int      `yaml:"age"`
+		Hobbies []string `yaml:"hobbies"`
+	}
+
+	yaml1 := YAML{Data: Sample{Name: "John", Age: 28, Hobbies: []string{"cycling", "reading"}}}
+	err := yaml1.Render(buf)
+	assert.FailOnError(t, err, "")
+	assert.Equal(t, "name: John\nage: 28\nhobbies:\n- cycling\n- reading\n", buf.String())
+}
+
+type testCents int64
+
+func (c *testCents) MarshalText() ([]byte, error) {
+	return []byte(fmt.Sprintf("%d.%02d", *c/100, *c%100)), nil
+}
+
+func TestRenderCSV(t *testing.T) {
+	buf := &bytes.Buffer{}
+
+	type Order struct {
+		ID       string     `csv:"order_id"`
+		Amount   float64    `csv:"amount"`
+		Customer string
+		Note     string     `csv:"-"`
+		Shipped  *time.Time `csv:"shipped"`
+		internal string
+	}
+
+	shipped := time.Date(2017, 5, 21, 10, 30, 0, 0, time.UTC)
+	orders := []*Order{
+		{ID: "1001", Amount: 20.5, Customer: "John, Doe", Note: "skip", Shipped: &shipped},
+		{ID: "1002", Amount: 8, Customer: "Jane", internal: "skip"},
+		nil,
+	}
+
+	csv1 := CSV{Data: orders}
+	assert.True(t, csv1.IsStreamable())
+	err := csv1.Render(buf)
+	assert.FailOnError(t, err, "")
+	assert.Equal(t, "order_id,amount,Customer,shipped\n"+
+		"1001,20.5,\"John, Doe\",2017-05-21T10:30:00Z\n"+
+		"1002,8,Jane,\n"+
+		",,,\n", buf.String())
+
+	// records
+	buf.Reset()
+	csv2 := CSV{Data: [][]string{{"name", "age"}, {"John", "28"}}}
+	err = csv2.Render(buf)
+	assert.FailOnError(t, err, "")
+	assert.Equal(t, "name,age\nJohn,28\n", buf.String())
+
+	// unsupported
+	err = (&CSV{Data: map[string]string{"name": "John"}}).Render(buf)
+	assert.Equal(t, "csv: unsupported data type 'map[string]string'", err.Error())
+
+	err = (&CSV{Data: []string{"John"}}).Render(buf)
+	assert.Equal(t, "csv: unsupported data type '[]string'", err.Error())
+
+	// pointer receiver text marshaler
+	type Payment struct {
+		Total    testCents
+		Discount *testCents
+	}
+	discount := testCents(250)
+	buf.Reset()
+	err = (&CSV{Data: []Payment{{Total: 1999, Discount: &discount}, {Total: 5}}}).Render(buf)
+	assert.FailOnError(t, err, "")
+	assert.Equal(t, "Total,Discount\n19.99,2.50\n0.05,\n", buf.String())
+}
+
+func TestRenderMsgPack(t *testing.T) {
+	buf := &bytes.Buffer{}
+
+	type Sample struct {
+		Name string `msgpack:"name"`
+		Age  int    `msgpack:"age"`
+	}
+
+	msgpack1 := MsgPack{Data: Sample{Name: "John", Age: 28}}
+	err := msgpack1.Render(buf)
+	assert.FailOnError(t, err, "")
+
+	var sample Sample
+	err = msgpack.Unmarshal(buf.Bytes(), &sample)
+	assert.FailOnError(t, err, "")
+	assert.Equal(t, "John", sample.Name)
+	assert.Equal(t, 28, sample.Age)
+}
+
 func TestRenderFailureXML(t *testing.T) {
 	buf := &bytes.Buffer{}
 	appConfig = getRenderCfg()
@@ -198,6 +327,21 @@ Each incoming request passes through a pre-defined list of steps
 	assert.NotNil(t, err)
 	assert.True(t, strings.Contains(err.Error(), "file-not-exists.txt: no such file or directory"))
 	assert.True(t, ess.IsStrEmpty(buf.String()))
+
+	// Streamable
+	assert.True(t, file1.IsStreamable())
+	assert.True(t, file2.IsStreamable())
+	assert.False(t, file3.IsStreamable())
+	assert.False(t, (&Binary{Reader: bytes.NewReader([]byte("aah"))}).IsStreamable())
+
+	// Seekable, reader must be marked
+	assert.False(t, file1.IsSeekable())
+	assert.True(t, file2.IsSeekable())
+	assert.False(t, file3.IsSeekable())
+	assert.False(t, (&Binary{Reader: ioutil.NopCloser(strings.NewReader("aah"))}).IsSeekable())
+	assert.False(t, (&Binary{}).IsSeekable())
+	assert.False(t, (&Binary{Reader: bytes.NewReader([]byte("aah"))}).IsSeekable())
+	assert.True(t, (&Binary{Reader: bytes.NewReader([]byte("aah")), Seekable: true}).IsSeekable())
 }
 
 func TestHTMLRender(t *testing.T) {
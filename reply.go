@@ -25,6 +25,7 @@ type Reply struct {
 	path     string
 	done     bool
 	gzip     bool
+	noCache  bool
 }
 
 //‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾
@@ -117,6 +118,16 @@ func (r *Reply) Conflict() *Reply {
 	return r.Status(http.StatusConflict)
 }
 
+// RequestEntityTooLarge method sets the HTTP Code as 413 RFC 7231, 6.5.11.
+func (r *Reply) RequestEntityTooLarge() *Reply {
+	return r.Status(http.StatusRequestEntityTooLarge)
+}
+
+// UnsupportedMediaType method sets the HTTP Code as 415 RFC 7231, 6.5.13.
+func (r *Reply) UnsupportedMediaType() *Reply {
+	return r.Status(http.StatusUnsupportedMediaType)
+}
+
 // InternalServerError method sets the HTTP Code as 500 RFC 7231, 6.6.1.
 func (r *Reply) InternalServerError() *Reply {
 	return r.Status(http.StatusInternalServerError)
@@ -144,16 +155,25 @@ func (r *Reply) ContentType(contentType string) *Reply {
 
 // JSON method renders given data as JSON response.
 // Also it sets HTTP 'Content-Type' as 'application/json; charset=utf-8'.
-// Response rendered pretty if 'render.pretty' is true.
+// Response rendered as per JSON options `render.json.*` from aah.conf.
 func (r *Reply) JSON(data interface{}) *Reply {
 	r.Rdr = &JSON{Data: data}
 	r.ContentType(ahttp.ContentTypeJSON.Raw())
 	return r
 }
 
+// JSONWithOptions method renders given data as JSON response with given
+// options instead of application JSON options, refer `aah.AppJSONOptions`.
+// Also it sets HTTP 'Content-Type' as 'application/json; charset=utf-8'.
+func (r *Reply) JSONWithOptions(data interface{}, opts *JSONOptions) *Reply {
+	r.Rdr = &JSON{Data: data, Options: opts}
+	r.ContentType(ahttp.ContentTypeJSON.Raw())
+	return r
+}
+
 // JSONP method renders given data as JSONP response with callback.
 // Also it sets HTTP 'Content-Type' as 'application/json; charset=utf-8'.
-// Response rendered pretty if 'render.pretty' is true.
+// Response rendered as per JSON options `render.json.*` from aah.conf.
 // Note: If `callback` param is empty and `callback` query param is exists then
 // query param value will be used.
 func (r *Reply) JSONP(data interface{}, callback string) *Reply {
@@ -162,6 +182,58 @@ func (r *Reply) JSONP(data interface{}, callback string) *Reply {
 	return r
 }
 
+// Problem method renders given problem details as JSON response as per
+// RFC 7807. Also it sets HTTP Content-Type as
+// 'application/problem+json; charset=utf-8' and HTTP Code from
+// `Problem.Status` if it's set.
+func (r *Reply) Problem(problem *Problem) *Reply {
+	if problem.Status > 0 {
+		r.Status(problem.Status)
+	}
+
+	r.Rdr = problem
+	r.ContentType(contentTypeProblemJSON)
+	return r
+}
+
+// Negotiate method renders the given data in the format chosen by request
+// `Accept` header quality values from renderer registry, such as JSON, XML,
+// Text, YAML, CSV, MessagePack and renderers added via `aah.AddRenderer`. Reply is
+// `406 Not Acceptable` if none of the renderers matches.
+//		For Example:
+//
+//		// Accept: application/xml;q=0.9, application/json
+//		c.Reply().Negotiate(user) // renders JSON
+func (r *Reply) Negotiate(data interface{}) *Reply {
+	r.Rdr = &negotiateRender{Data: data}
+	return r
+}
+
+// SSE method streams the Server-Sent Events to the client via given handler.
+// Also it sets HTTP Content-Type as 'text/event-stream; charset=utf-8'.
+// Events are flushed immediately without buffering and gzip, keep-alive
+// comment is sent as per aah.conf `render.sse.keep_alive`.
+//		For Example:
+//
+//		c.Reply().SSE(func(stream *aah.EventStream) {
+//			for {
+//				select {
+//				case <-stream.Done():
+//					return
+//				case stats := <-statsChan:
+//					if err := stream.Send(&aah.SSEvent{Event: "stats", Data: stats}); err != nil {
+//						return
+//					}
+//				}
+//			}
+//		})
+func (r *Reply) SSE(handler SSEHandler) *Reply {
+	r.Rdr = &SSE{Handler: handler, KeepAlive: sseKeepAlive()}
+	r.ContentType(contentTypeEventStream)
+	r.gzip = false
+	return r
+}
+
 // XML method renders given data as XML response. Also it sets
 // HTTP Content-Type as 'application/xml; charset=utf-8'.
 // Response rendered pretty if 'render.pretty' is true.
@@ -171,6 +243,44 @@ func (r *Reply) XML(data interface{}) *Reply {
 	return r
 }
 
+// YAML method renders given data as YAML response. Also it sets
+// HTTP Content-Type as 'application/yaml; charset=utf-8'. YAML is always
+// rendered in block style, so 'render.pretty' is not applicable.
+func (r *Reply) YAML(data interface{}) *Reply {
+	r.Rdr = &YAML{Data: data}
+	r.ContentType(contentTypeYAML)
+	return r
+}
+
+// CSV method renders given `[][]string` or slice of structs as CSV response,
+// header row is composed from struct field tag `csv`. Also it sets HTTP
+// Content-Type as 'text/csv; charset=utf-8'. Rows are streamed on the wire
+// without buffering if `render.stream.enable` is true in aah.conf.
+//		For Example:
+//
+//		type Order struct {
+//			ID     string  `csv:"order_id"`
+//			Amount float64 `csv:"amount"`
+//			Note   string  `csv:"-"`
+//		}
+//
+//		c.Reply().
+//			Header(ahttp.HeaderContentDisposition, "attachment; filename=orders.csv").
+//			CSV(orders)
+func (r *Reply) CSV(data interface{}) *Reply {
+	r.Rdr = &CSV{Data: data}
+	r.ContentType(contentTypeCSV)
+	return r
+}
+
+// MsgPack method renders given data as MessagePack response. Also it sets
+// HTTP Content-Type as 'application/msgpack'.
+func (r *Reply) MsgPack(data interface{}) *Reply {
+	r.Rdr = &MsgPack{Data: data}
+	r.ContentType(contentTypeMsgPack)
+	return r
+}
+
 // Text method renders given data as Plain Text response with given values.
 // Also it sets HTTP Content-Type as 'text/plain; charset=utf-8'.
 func (r *Reply) Text(format string, values ...interface{}) *Reply {
@@ -187,14 +297,26 @@ func (r *Reply) Binary(b []byte) *Reply {
 
 // Readfrom method reads the data from given reader and writes into response.
 // It auto-detects the content type of the file if `Content-Type` is not set.
+// Response is streamed on the wire without buffering if `render.stream.enable`
+// is true in aah.conf, except in-memory readers.
 // Note: Method will close the reader after serving if it's satisfies the `io.Closer`.
 func (r *Reply) Readfrom(reader io.Reader) *Reply {
 	r.Rdr = &Binary{Reader: reader}
 	return r
 }
 
+// ReadSeekfrom method reads the data from given seekable reader and writes
+// into response with HTTP Range and conditional requests support. It
+// auto-detects the content type if `Content-Type` is not set.
+// Note: Method will close the reader after serving if it's satisfies the `io.Closer`.
+func (r *Reply) ReadSeekfrom(reader io.ReadSeeker) *Reply {
+	r.Rdr = &Binary{Reader: reader, Seekable: true}
+	return r
+}
+
 // File method send the given as file to client. It auto-detects the content type
-// of the file if `Content-Type` is not set.
+// of the file if `Content-Type` is not set. Response is streamed on the wire
+// with HTTP Range, `Last-Modified`, `ETag` and conditional requests support.
 func (r *Reply) File(file string) *Reply {
 	r.Rdr = &Binary{Path: file}
 	return r
@@ -335,6 +457,13 @@ func (r *Reply) DisableGzip() *Reply {
 	return r
 }
 
+// DisableCache method allows you to skip the reply from response cache. For
+// e.g.: reply has user specific content on cache enabled route.
+func (r *Reply) DisableCache() *Reply {
+	r.noCache = true
+	return r
+}
+
 // IsContentTypeSet method returns true if Content-Type is set otherwise
 // false.
 func (r *Reply) IsContentTypeSet() bool {
@@ -362,4 +491,5 @@ func (r *Reply) Reset() {
 	r.path = ""
 	r.done = false
 	r.gzip = true
+	r.noCache = false
 }
@@ -63,6 +63,12 @@ func TestReplyStatusCodes(t *testing.T) {
 	re.Conflict()
 	assert.Equal(t, http.StatusConflict, re.Code)
 
+	re.RequestEntityTooLarge()
+	assert.Equal(t, http.StatusRequestEntityTooLarge, re.Code)
+
+	re.UnsupportedMediaType()
+	assert.Equal(t, http.StatusUnsupportedMediaType, re.Code)
+
 	re.InternalServerError()
 	assert.Equal(t, http.StatusInternalServerError, re.Code)
 
@@ -93,6 +99,7 @@ func TestReplyText(t *testing.T) {
 func TestReplyJSON(t *testing.T) {
 	buf, re1 := getBufferAndReply()
 	appConfig = getReplyRenderCfg()
+	assert.Nil(t, initJSONOptions(appConfig))
 
 	data := struct {
 		Name    string
@@ -124,6 +131,7 @@ func TestReplyJSON(t *testing.T) {
 	buf.Reset()
 
 	appConfig.SetBool("render.pretty", false)
+	assert.Nil(t, initJSONOptions(appConfig))
 
 	err = re1.Rdr.Render(buf)
 	assert.FailOnError(t, err, "")
@@ -134,6 +142,7 @@ func TestReplyJSON(t *testing.T) {
 func TestReplyJSONP(t *testing.T) {
 	buf, re1 := getBufferAndReply()
 	appConfig = getReplyRenderCfg()
+	assert.Nil(t, initJSONOptions(appConfig))
 
 	data := struct {
 		Name    string
@@ -162,6 +171,7 @@ func TestReplyJSONP(t *testing.T) {
 	buf.Reset()
 
 	appConfig.SetBool("render.pretty", false)
+	assert.Nil(t, initJSONOptions(appConfig))
 
 	err = re1.Rdr.Render(buf)
 	assert.FailOnError(t, err, "")
@@ -169,6 +179,23 @@ func TestReplyJSONP(t *testing.T) {
 		buf.String())
 }
 
+func TestReplyProblem(t *testing.T) {
+	buf, re1 := getBufferAndReply()
+	appConfig, _ = config.ParseString("")
+
+	re1.Problem(&Problem{Title: "Conflict", Status: http.StatusConflict, Detail: "user already exists"})
+	assert.Equal(t, http.StatusConflict, re1.Code)
+	assert.Equal(t, "application/problem+json; charset=utf-8", re1.ContType)
+
+	err := re1.Rdr.Render(buf)
+	assert.FailOnError(t, err, "")
+	assert.Equal(t, `{"detail":"user already exists","status":409,"title":"Conflict"}`, buf.String())
+
+	// status is not changed if not set in problem
+	re1.Ok().Problem(&Problem{Title: "Partial"})
+	assert.Equal(t, http.StatusOK, re1.Code)
+}
+
 func TestReplyXML(t *testing.T) {
 	buf, re1 := getBufferAndReply()
 	appConfig = getReplyRenderCfg()
@@ -206,6 +233,30 @@ func TestReplyXML(t *testing.T) {
 		buf.String())
 }
 
+func TestReplyYAMLCSVMsgPack(t *testing.T) {
+	buf, re1 := getBufferAndReply()
+
+	re1.YAML(Data{"name": "John"})
+	assert.Equal(t, "application/yaml; charset=utf-8", re1.ContType)
+	err := re1.Rdr.Render(buf)
+	assert.FailOnError(t, err, "")
+	assert.Equal(t, "name: John\n", buf.String())
+
+	buf.Reset()
+	re1.CSV([][]string{{"name"}, {"John"}})
+	assert.Equal(t, "text/csv; charset=utf-8", re1.ContType)
+	err = re1.Rdr.Render(buf)
+	assert.FailOnError(t, err, "")
+	assert.Equal(t, "name\nJohn\n", buf.String())
+
+	buf.Reset()
+	re1.MsgPack(Data{"name": "John"})
+	assert.Equal(t, "application/msgpack", re1.ContType)
+	err = re1.Rdr.Render(buf)
+	assert.FailOnError(t, err, "")
+	assert.Equal(t, "\x81\xa4name\xa4John", buf.String())
+}
+
 func TestReplyReadfrom(t *testing.T) {
 	buf, re1 := getBufferAndReply()
 	re1.ContentType(ahttp.ContentTypeOctetStream.Raw()).
@@ -220,6 +271,11 @@ func TestReplyReadfrom(t *testing.T) {
 	assert.FailOnError(t, err, "")
 	assert.Equal(t, `<Sample><Name>John</Name><Age>28</Age><Address>this is my street</Address></Sample>`,
 		buf.String())
+	assert.False(t, re1.Rdr.(*Binary).IsSeekable())
+
+	_, re2 := getBufferAndReply()
+	re2.ReadSeekfrom(strings.NewReader("aah web framework"))
+	assert.True(t, re2.Rdr.(*Binary).IsSeekable())
 }
 
 func TestReplyFileDownload(t *testing.T) {
@@ -8,6 +8,7 @@ import (
 	"errors"
 	"fmt"
 	"html/template"
+	"net/http"
 	"path/filepath"
 	"reflect"
 	"strings"
@@ -19,7 +20,15 @@ import (
 	"aahframework.org/router.v0"
 )
 
-var appRouter *router.Router
+var (
+	appRouter *router.Router
+
+	// appRoutesConfig and appRouteConfigPaths holds the route level
+	// configuration from routes.conf which are not part of `router.Route`.
+	// For e.g.: `content_types`, `max_body_size`, etc.
+	appRoutesConfig     *config.Config
+	appRouteConfigPaths map[string]string
+)
 
 //‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾
 // Global methods
@@ -42,9 +51,82 @@ func initRoutes(cfgDir string, appCfg *config.Config) error {
 		return fmt.Errorf("routes.conf: %s", err)
 	}
 
+	return initRouteConfigs(routesPath)
+}
+
+// initRouteConfigs method loads the routes.conf and indexes the route config
+// path by domain host and route name.
+func initRouteConfigs(routesPath string) error {
+	routesCfg, err := config.LoadFile(routesPath)
+	if err != nil {
+		return fmt.Errorf("routes.conf: %s", err)
+	}
+
+	appRoutesConfig = routesCfg
+	appRouteConfigPaths = make(map[string]string)
+	for _, domainKey := range routesCfg.KeysByPath("domains") {
+		domainPath := "domains." + domainKey
+		host := routesCfg.StringDefault(domainPath+".host", "")
+		addRouteConfigPaths(routesCfg, host, domainPath+".routes")
+	}
+
 	return nil
 }
 
+func addRouteConfigPaths(routesCfg *config.Config, host, routesPath string) {
+	for _, routeName := range routesCfg.KeysByPath(routesPath) {
+		routePath := routesPath + "." + routeName
+		appRouteConfigPaths[routeConfigKey(host, routeName)] = routePath
+
+		// child routes
+		if routesCfg.IsExists(routePath + ".routes") {
+			addRouteConfigPaths(routesCfg, host, routePath+".routes")
+		}
+	}
+}
+
+func routeConfigKey(host, routeName string) string {
+	return host + ":" + routeName
+}
+
+// routeConfigPath method returns the routes.conf config path for the current
+// request route otherwise empty string.
+func routeConfigPath(ctx *Context) string {
+	if ctx.domain == nil || ctx.route == nil {
+		return ""
+	}
+	return appRouteConfigPaths[routeConfigKey(ctx.domain.Host, ctx.route.Name)]
+}
+
+// routeStringDefault method returns the route config string value for the
+// given key from routes.conf otherwise the given default value.
+func routeStringDefault(ctx *Context, key, defaultValue string) string {
+	if path := routeConfigPath(ctx); !ess.IsStrEmpty(path) {
+		return appRoutesConfig.StringDefault(path+"."+key, defaultValue)
+	}
+	return defaultValue
+}
+
+// routeBoolDefault method returns the route config bool value for the
+// given key from routes.conf otherwise the given default value.
+func routeBoolDefault(ctx *Context, key string, defaultValue bool) bool {
+	if path := routeConfigPath(ctx); !ess.IsStrEmpty(path) {
+		return appRoutesConfig.BoolDefault(path+"."+key, defaultValue)
+	}
+	return defaultValue
+}
+
+// routeStringList method returns the route config string list for the
+// given key from routes.conf otherwise the given default value.
+func routeStringList(ctx *Context, key string, defaultValue []string) []string {
+	if path := routeConfigPath(ctx); !ess.IsStrEmpty(path) {
+		if values, found := appRoutesConfig.StringList(path + "." + key); found {
+			return values
+		}
+	}
+	return defaultValue
+}
+
 func appendAnchorLink(routePath, anchorLink string) string {
 	if ess.IsStrEmpty(anchorLink) {
 		return routePath
@@ -148,7 +230,7 @@ func handleRtsOptionsMna(ctx *Context, domain *router.Domain, rts bool) error {
 			}
 
 			reply.Redirect(ctx.Req.Raw.URL.String())
-			log.Debugf("RedirectTrailingSlash: %d, %s ==> %s", reply.Code, reqPath, reply.path)
+			ctx.Log().Debugf("RedirectTrailingSlash: %d, %s ==> %s", reply.Code, reqPath, reply.path)
 			return nil
 		}
 	}
@@ -158,7 +240,7 @@ func handleRtsOptionsMna(ctx *Context, domain *router.Domain, rts bool) error {
 		if domain.AutoOptions {
 			if allowed := domain.Allowed(reqMethod, reqPath); !ess.IsStrEmpty(allowed) {
 				allowed += ", " + ahttp.MethodOptions
-				log.Debugf("Auto 'OPTIONS' allowed HTTP Methods: %s", allowed)
+				ctx.Log().Debugf("Auto 'OPTIONS' allowed HTTP Methods: %s", allowed)
 				reply.Header(ahttp.HeaderAllow, allowed)
 				return nil
 			}
@@ -169,10 +251,9 @@ func handleRtsOptionsMna(ctx *Context, domain *router.Domain, rts bool) error {
 	if domain.MethodNotAllowed {
 		if allowed := domain.Allowed(reqMethod, reqPath); !ess.IsStrEmpty(allowed) {
 			allowed += ", " + ahttp.MethodOptions
-			log.Debugf("Allowed HTTP Methods for 405 response: %s", allowed)
-			reply.MethodNotAllowed().
-				Header(ahttp.HeaderAllow, allowed).
-				Text("405 Method Not Allowed")
+			ctx.Log().Debugf("Allowed HTTP Methods for 405 response: %s", allowed)
+			reply.Header(ahttp.HeaderAllow, allowed)
+			handleError(ctx, &HTTPError{Code: http.StatusMethodNotAllowed})
 			return nil
 		}
 	}
@@ -185,21 +266,21 @@ func handleRtsOptionsMna(ctx *Context, domain *router.Domain, rts bool) error {
 func handleRouteNotFound(ctx *Context, domain *router.Domain, route *router.Route) {
 	// handle effectively to reduce heap allocation
 	if domain.NotFoundRoute == nil {
-		log.Warnf("Route not found: %s, isStaticRoute: false", ctx.Req.Path)
-		ctx.Reply().NotFound().Text("404 Not Found")
+		ctx.Log().Warnf("Route not found: %s, isStaticRoute: false", ctx.Req.Path)
+		handleError(ctx, &HTTPError{Code: http.StatusNotFound})
 		return
 	}
 
-	log.Warnf("Route not found: %s, isStaticRoute: %v", ctx.Req.Path, route.IsStatic)
+	ctx.Log().Warnf("Route not found: %s, isStaticRoute: %v", ctx.Req.Path, route.IsStatic)
 	if err := ctx.setTarget(route); err == errTargetNotFound {
-		ctx.Reply().NotFound().Text("404 Not Found")
+		handleError(ctx, &HTTPError{Code: http.StatusNotFound})
 		return
 	}
 
 	target := reflect.ValueOf(ctx.target)
 	notFoundAction := target.MethodByName(ctx.action.Name)
 
-	log.Debugf("Calling user defined not-found action: %s.%s", ctx.controller, ctx.action.Name)
+	ctx.Log().Debugf("Calling user defined not-found action: %s.%s", ctx.controller, ctx.action.Name)
 	notFoundAction.Call(emptyArg)
 }
 
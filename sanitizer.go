@@ -0,0 +1,133 @@
+// Copyright (c) Jeevanandam M. (https://github.com/jeevatkm)
+// go-aah/aah source code and usage is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package aah
+
+import (
+	"fmt"
+	"net/url"
+
+	"github.com/microcosm-cc/bluemonday"
+
+	"aahframework.org/config.v0"
+	"aahframework.org/essentials.v0"
+	"aahframework.org/log.v0"
+)
+
+const (
+	sanitizePolicyNone   = "none"
+	sanitizePolicyStrip  = "strip"
+	sanitizePolicyBasic  = "basic"
+	sanitizePolicyCustom = "custom"
+)
+
+var (
+	sanitizePolicies   = make(map[string]*bluemonday.Policy)
+	sanitizePolicy     = sanitizePolicyNone
+	sanitizeSkipFields []string
+)
+
+//‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾
+// Unexported methods
+//___________________________________
+
+// initSanitizer method creates the HTML sanitize policies and reads the
+// `request.sanitize` configuration from aah.conf. Supported policies are -
+//  - none   - values are not sanitized
+//  - strip  - strips all the HTML elements
+//  - basic  - allows basic formatting elements such as `b`, `i`, `p`, `ul`, etc.
+//  - custom - allows the elements and attributes from `request.sanitize.custom`
+func initSanitizer(appCfg *config.Config) error {
+	sanitizePolicies = map[string]*bluemonday.Policy{
+		sanitizePolicyStrip: bluemonday.StrictPolicy(),
+		sanitizePolicyBasic: basicSanitizePolicy(),
+	}
+
+	if elements, found := appCfg.StringList("request.sanitize.custom.elements"); found {
+		policy := bluemonday.NewPolicy()
+		policy.AllowElements(elements...)
+		if attributes, found := appCfg.StringList("request.sanitize.custom.attributes"); found {
+			policy.AllowAttrs(attributes...).OnElements(elements...)
+		}
+		policy.AllowStandardURLs()
+		sanitizePolicies[sanitizePolicyCustom] = policy
+	}
+
+	sanitizePolicy = appCfg.StringDefault("request.sanitize.policy", sanitizePolicyNone)
+	if err := checkSanitizePolicy(sanitizePolicy); err != nil {
+		return fmt.Errorf("'request.sanitize.policy' %s", err)
+	}
+	sanitizeSkipFields, _ = appCfg.StringList("request.sanitize.skip_fields")
+
+	// validate the route level policies
+	for _, path := range appRouteConfigPaths {
+		key := path + ".sanitize.policy"
+		if err := checkSanitizePolicy(appRoutesConfig.StringDefault(key, sanitizePolicy)); err != nil {
+			return fmt.Errorf("'%s' %s", key, err)
+		}
+	}
+
+	return nil
+}
+
+// basicSanitizePolicy method returns the policy which allows the basic text
+// formatting elements.
+func basicSanitizePolicy() *bluemonday.Policy {
+	policy := bluemonday.NewPolicy()
+	policy.AllowElements("b", "i", "u", "s", "em", "strong", "small", "sub",
+		"sup", "p", "br", "hr", "ul", "ol", "li", "blockquote", "code", "pre")
+	return policy
+}
+
+func checkSanitizePolicy(name string) error {
+	if name == sanitizePolicyNone {
+		return nil
+	}
+
+	if _, found := sanitizePolicies[name]; !found {
+		return fmt.Errorf("sanitize policy '%s' not exists", name)
+	}
+	return nil
+}
+
+// sanitizeFormParams method sanitizes the Form and Multipart Form values
+// with policy of the route `sanitize.policy` from routes.conf otherwise
+// `request.sanitize.policy` from aah.conf. Fields `sanitize.skip_fields`
+// are not sanitized.
+//
+// Sanitized values are HTML-escaped text, i.e. `O'Brien` becomes
+// `O&#39;Brien`. Values are not unescaped, since it turns the escaped markup
+// into live markup.
+//
+// Sanitized values are set into new `ctx.Req.Params.Form`, so raw values are
+// still reachable via `ctx.Req.Raw.Form` and `ctx.Req.Raw.MultipartForm`.
+func sanitizeFormParams(ctx *Context) {
+	if len(ctx.Req.Params.Form) == 0 {
+		return
+	}
+
+	name := routeStringDefault(ctx, "sanitize.policy", sanitizePolicy)
+	policy, found := sanitizePolicies[name]
+	if !found {
+		return
+	}
+
+	log.Debugf("Sanitizing form values with policy '%s': %s", name, ctx.Req.Path)
+	skipFields := routeStringList(ctx, "sanitize.skip_fields", sanitizeSkipFields)
+	form := make(url.Values, len(ctx.Req.Params.Form))
+	for key, values := range ctx.Req.Params.Form {
+		if ess.IsSliceContainsString(skipFields, key) {
+			form[key] = values
+			continue
+		}
+
+		sanitized := make([]string, len(values))
+		for idx, value := range values {
+			sanitized[idx] = policy.Sanitize(value)
+		}
+		form[key] = sanitized
+	}
+
+	ctx.Req.Params.Form = form
+}
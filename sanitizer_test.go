@@ -0,0 +1,103 @@
+// Copyright (c) Jeevanandam M. (https://github.com/jeevatkm)
+// go-aah/aah source code and usage is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package aah
+
+import (
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"aahframework.org/ahttp.v0"
+	"aahframework.org/config.v0"
+	"aahframework.org/router.v0"
+	"aahframework.org/test.v0/assert"
+)
+
+func TestSanitizerInit(t *testing.T) {
+	defer func() {
+		sanitizePolicy, sanitizeSkipFields = sanitizePolicyNone, nil
+	}()
+
+	cfg, _ := config.ParseString(`
+	request {
+		sanitize {
+			policy = "custom"
+			skip_fields = ["content"]
+			custom {
+				elements = ["a"]
+				attributes = ["href"]
+			}
+		}
+	}`)
+	err := initSanitizer(cfg)
+	assert.Nil(t, err)
+	assert.Equal(t, "custom", sanitizePolicy)
+	assert.Equal(t, []string{"content"}, sanitizeSkipFields)
+	assert.NotNil(t, sanitizePolicies[sanitizePolicyStrip])
+	assert.NotNil(t, sanitizePolicies[sanitizePolicyBasic])
+	assert.Equal(t, `<a href="https://aahframework.org" rel="nofollow">aah</a>`,
+		sanitizePolicies[sanitizePolicyCustom].Sanitize(`<a href="https://aahframework.org" onclick="alert(1)"><b>aah</b></a>`))
+
+	cfg.SetString("request.sanitize.policy", "unknown")
+	err = initSanitizer(cfg)
+	assert.NotNil(t, err)
+	assert.Equal(t, "'request.sanitize.policy' sanitize policy 'unknown' not exists", err.Error())
+}
+
+func TestSanitizerFormParams(t *testing.T) {
+	appRoutesConfig, _ = config.ParseString("")
+	appRoutesConfig.SetString("domains.localhost.routes.admin_post.sanitize.policy", "none")
+	appRouteConfigPaths = map[string]string{
+		routeConfigKey("localhost", "admin_post"): "domains.localhost.routes.admin_post",
+	}
+	defer func() {
+		appRoutesConfig, appRouteConfigPaths = nil, nil
+		sanitizePolicy, sanitizeSkipFields = sanitizePolicyNone, nil
+	}()
+
+	cfg, _ := config.ParseString("")
+	cfg.SetString("request.sanitize.policy", "basic")
+	assert.Nil(t, initSanitizer(cfg))
+	sanitizeSkipFields = []string{"raw"}
+
+	form := url.Values{
+		"title": []string{`<script>alert('aah')</script><b>Hello</b>`},
+		"body":  []string{`<p onclick="alert(1)">aah <i>web</i></p>`, `<img src="x.png">framework`},
+		"raw":   []string{`<script>raw</script>`},
+	}
+	req := httptest.NewRequest("POST", "http://localhost:8080/posts", nil)
+	ctx := &Context{Req: ahttp.ParseRequest(req, &ahttp.Request{})}
+	ctx.Req.Params.Form = form
+
+	sanitizeFormParams(ctx)
+	assert.Equal(t, "<b>Hello</b>", ctx.Req.Params.FormValue("title"))
+	assert.Equal(t, []string{"<p>aah <i>web</i></p>", "framework"}, ctx.Req.Params.Form["body"])
+	assert.Equal(t, "<script>raw</script>", ctx.Req.Params.FormValue("raw"))
+
+	// raw values are untouched
+	assert.Equal(t, `<script>alert('aah')</script><b>Hello</b>`, form.Get("title"))
+
+	// route opt-out
+	ctx.Req.Params.Form = form
+	ctx.domain = &router.Domain{Host: "localhost"}
+	ctx.route = &router.Route{Name: "admin_post"}
+	sanitizeFormParams(ctx)
+	assert.Equal(t, `<script>alert('aah')</script><b>Hello</b>`, ctx.Req.Params.FormValue("title"))
+
+	// strip policy values are HTML-escaped text, entity-encoded markup
+	// stays escaped
+	sanitizePolicy = sanitizePolicyStrip
+	ctx.route = &router.Route{Name: "posts"}
+	ctx.Req.Params.Form = url.Values{
+		"name":  []string{"O'Brien"},
+		"title": []string{`<b>a & b</b>`},
+		"body":  []string{`&lt;script&gt;alert(1)&lt;/script&gt;`},
+	}
+	sanitizeFormParams(ctx)
+	assert.Equal(t, "O&#39;Brien", ctx.Req.Params.FormValue("name"))
+	assert.Equal(t, "a &amp; b", ctx.Req.Params.FormValue("title"))
+	assert.False(t, strings.Contains(ctx.Req.Params.FormValue("body"), "<script"))
+}
@@ -80,6 +80,12 @@ func Start() {
 	go writePID(getBinaryFileName(), AppBaseDir())
 	go listenSignals()
 
+	// Metrics on separate listener
+	startMetricsServer()
+
+	// Health and readiness on separate listener
+	startHealthServer()
+
 	// Unix Socket
 	if strings.HasPrefix(AppHTTPAddress(), "unix") {
 		startUnix(AppHTTPAddress())
@@ -102,7 +108,10 @@ func Start() {
 // in seconds. It's invoked on OS signal `SIGINT` and `SIGTERM`.
 //
 // Method performs:
+//    - Marks aah server as not ready, readiness endpoint reports down
 //    - Graceful server shutdown with timeout by `server.timeout.grace_shutdown`
+//    - Writes the buffered access log entries
+//    - Closes the metrics and health listeners
 //    - Publishes `OnShutdown` event
 //    - Exits program with code 0
 func Shutdown() {
@@ -112,12 +121,22 @@ func Shutdown() {
 		graceTime = "60s"
 	}
 
+	// Readiness endpoint reports down during graceful shutdown
+	setAppReady(false)
+
 	graceTimeout, _ := time.ParseDuration(graceTime)
 	ctx, cancel := context.WithTimeout(context.Background(), graceTimeout)
 	if err := aahServer.Shutdown(ctx); err != nil && err != http.ErrServerClosed {
 		log.Error(err)
 	}
 
+	// Write the buffered access log entries
+	closeAccessLog()
+
+	// Close the metrics and health listeners
+	closeMetricsServer()
+	closeHealthServer()
+
 	// Publish `OnShutdown` event
 	AppEventStore().sortAndPublishSync(&Event{Name: EventOnShutdown})
 
@@ -142,6 +161,9 @@ func startUnix(address string) {
 
 	aahServer.Addr = address
 	log.Infof("aah go server running on %v", aahServer.Addr)
+
+	// Ready to serve the requests, readiness endpoint reports up
+	setAppReady(true)
 	if err := aahServer.Serve(listener); err != nil && err != http.ErrServerClosed {
 		log.Error(err)
 	}
@@ -175,19 +197,52 @@ func startHTTPS() {
 		aahServer.TLSConfig.NextProtos = append(aahServer.TLSConfig.NextProtos, "h2")
 	}
 
+	listener := listenTCP()
 	log.Infof("aah go server running on %v", aahServer.Addr)
-	if err := aahServer.ListenAndServeTLS(appSSLCert, appSSLKey); err != nil && err != http.ErrServerClosed {
+
+	// Ready to serve the requests, readiness endpoint reports up
+	setAppReady(true)
+	if err := aahServer.ServeTLS(listener, appSSLCert, appSSLKey); err != nil && err != http.ErrServerClosed {
 		log.Error(err)
 	}
 }
 
 func startHTTP() {
+	listener := listenTCP()
 	log.Infof("aah go server running on %v", aahServer.Addr)
-	if err := aahServer.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+
+	// Ready to serve the requests, readiness endpoint reports up
+	setAppReady(true)
+	if err := aahServer.Serve(listener); err != nil && err != http.ErrServerClosed {
 		log.Error(err)
 	}
 }
 
+// listenTCP method listens on the server address with TCP keep-alive, same
+// as `http.Server.ListenAndServe`. Listener is created before marking the
+// application ready.
+func listenTCP() net.Listener {
+	listener, err := net.Listen("tcp", aahServer.Addr)
+	logAsFatal(err)
+	return tcpKeepAliveListener{listener.(*net.TCPListener)}
+}
+
+// tcpKeepAliveListener sets TCP keep-alive on the accepted connections, so
+// dead connections eventually go away.
+type tcpKeepAliveListener struct {
+	*net.TCPListener
+}
+
+func (ln tcpKeepAliveListener) Accept() (net.Conn, error) {
+	tc, err := ln.AcceptTCP()
+	if err != nil {
+		return nil, err
+	}
+	_ = tc.SetKeepAlive(true)
+	_ = tc.SetKeepAlivePeriod(3 * time.Minute)
+	return tc, nil
+}
+
 // listenSignals method listens to OS signals for aah server Shutdown.
 func listenSignals() {
 	sc := make(chan os.Signal, 2)
@@ -5,6 +5,7 @@
 package aah
 
 import (
+	"net/http"
 	"path/filepath"
 	"testing"
 	"time"
@@ -68,3 +69,26 @@ func TestServerStart2(t *testing.T) {
 	AppConfig().SetString("server.port", "80")
 	Start()
 }
+
+func TestServerReadyAfterListen(t *testing.T) {
+	setAppReady(false)
+	defer setAppReady(false)
+
+	aahServer = &http.Server{Addr: "127.0.0.1:0", Handler: http.NotFoundHandler()}
+	defer func() { aahServer = nil }()
+	assert.False(t, AppIsReady())
+
+	done := make(chan struct{})
+	go func() {
+		startHTTP()
+		close(done)
+	}()
+
+	for start := time.Now(); !AppIsReady() && time.Since(start) < time.Second; {
+		time.Sleep(5 * time.Millisecond)
+	}
+	assert.True(t, AppIsReady())
+
+	assert.Nil(t, aahServer.Close())
+	<-done
+}
@@ -0,0 +1,147 @@
+// Copyright (c) Jeevanandam M. (https://github.com/jeevatkm)
+// go-aah/aah source code and usage is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package aah
+
+import (
+	"bytes"
+	"fmt"
+	"time"
+
+	"aahframework.org/config.v0"
+)
+
+// Request pipeline stages measured by slow request detector, in the order of
+// request pipeline.
+const (
+	stageRouting = iota
+	stageSession
+	stageParams
+	stageInterceptors
+	stageAction
+	stageRender
+	stageWrite
+	stageCount
+)
+
+var (
+	appSlowRequestThreshold    time.Duration
+	appSlowRequestPublishEvent bool
+
+	stageNames = [stageCount]string{"routing", "session", "params", "interceptors", "action", "render", "write"}
+)
+
+type (
+	// SlowRequest holds the details of request which exceeded the aah.conf
+	// `server.slow_request.threshold`, it's the data of `EventOnSlowRequest`.
+	SlowRequest struct {
+		RequestID string
+		Route     string
+		Method    string
+		Path      string
+		Total     time.Duration
+		Threshold time.Duration
+		Stages    []SlowRequestStage
+	}
+
+	// SlowRequestStage holds the time spent in the request pipeline stage.
+	SlowRequestStage struct {
+		Name     string
+		Duration time.Duration
+	}
+)
+
+//‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾
+// SlowRequest methods
+//___________________________________
+
+// String method returns the stage breakdown of slow request.
+//		For Example:
+//
+//		routing=120µs session=0s params=80µs interceptors=2ms action=1.2s render=15ms write=300µs
+func (sr *SlowRequest) String() string {
+	buf := &bytes.Buffer{}
+	for idx, stage := range sr.Stages {
+		if idx > 0 {
+			buf.WriteByte(' ')
+		}
+		buf.WriteString(stage.Name)
+		buf.WriteByte('=')
+		buf.WriteString(stage.Duration.String())
+	}
+	return buf.String()
+}
+
+//‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾
+// Unexported methods
+//___________________________________
+
+// pipelineStage method starts the tracing span and measures the time spent
+// in the request pipeline stage. Returned func ends both.
+//		For Example:
+//
+//		defer pipelineStage(ctx, "loadSession", stageSession)()
+func pipelineStage(ctx *Context, name string, stage int) func() {
+	endTrace := traceStage(ctx, name)
+	if appSlowRequestThreshold <= 0 {
+		return endTrace
+	}
+
+	start := time.Now()
+	return func() {
+		ctx.stages[stage] += time.Since(start)
+		endTrace()
+	}
+}
+
+// checkSlowRequest method logs the warning with stage breakdown if request
+// handling exceeded the slow request threshold. `EventOnSlowRequest` is
+// published if `server.slow_request.publish_event` is enabled.
+func checkSlowRequest(ctx *Context) {
+	if appSlowRequestThreshold <= 0 || ctx.startTime.IsZero() {
+		return
+	}
+
+	total := time.Since(ctx.startTime)
+	if total <= appSlowRequestThreshold {
+		return
+	}
+
+	sr := &SlowRequest{
+		RequestID: ctx.Req.Header.Get(appRequestIDHeader),
+		Method:    ctx.Req.Method,
+		Path:      ctx.Req.Path,
+		Total:     total,
+		Threshold: appSlowRequestThreshold,
+		Stages:    make([]SlowRequestStage, stageCount),
+	}
+	if ctx.route != nil {
+		sr.Route = ctx.route.Name
+	}
+	for idx := range sr.Stages {
+		sr.Stages[idx] = SlowRequestStage{Name: stageNames[idx], Duration: ctx.stages[idx]}
+	}
+
+	ctx.Log().Warnf("Slow request: %s %s took %s, threshold %s, %s",
+		sr.Method, sr.Path, sr.Total, sr.Threshold, sr)
+
+	if appSlowRequestPublishEvent {
+		PublishEvent(EventOnSlowRequest, sr)
+	}
+}
+
+// initSlowRequest method initializes the slow request detector from
+// aah.conf `server.slow_request`. Detector is disabled if threshold is zero.
+func initSlowRequest(appCfg *config.Config) error {
+	appSlowRequestThreshold, appSlowRequestPublishEvent = 0, false
+
+	threshold, err := time.ParseDuration(appCfg.StringDefault("server.slow_request.threshold", "0s"))
+	if err != nil {
+		return fmt.Errorf("'server.slow_request.threshold' value is not a valid time unit: %s", err)
+	}
+
+	appSlowRequestThreshold = threshold
+	appSlowRequestPublishEvent = appCfg.BoolDefault("server.slow_request.publish_event", false)
+	return nil
+}
@@ -0,0 +1,178 @@
+// Copyright (c) Jeevanandam M. (https://github.com/jeevatkm)
+// go-aah/aah source code and usage is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package aah
+
+import (
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"aahframework.org/config.v0"
+	"aahframework.org/router.v0"
+	"aahframework.org/test.v0/assert"
+)
+
+func TestSlowRequestInit(t *testing.T) {
+	defer func() { appSlowRequestThreshold, appSlowRequestPublishEvent = 0, false }()
+
+	cfg, _ := config.ParseString("")
+	assert.Nil(t, initSlowRequest(cfg))
+	assert.Equal(t, time.Duration(0), appSlowRequestThreshold)
+	assert.False(t, appSlowRequestPublishEvent)
+
+	cfg.SetString("server.slow_request.threshold", "500ms")
+	cfg.SetBool("server.slow_request.publish_event", true)
+	assert.Nil(t, initSlowRequest(cfg))
+	assert.Equal(t, 500*time.Millisecond, appSlowRequestThreshold)
+	assert.True(t, appSlowRequestPublishEvent)
+
+	cfg.SetString("server.slow_request.threshold", "500")
+	err := initSlowRequest(cfg)
+	assert.True(t, strings.HasPrefix(err.Error(), "'server.slow_request.threshold' value is not a valid time unit: "))
+	assert.Equal(t, time.Duration(0), appSlowRequestThreshold)
+}
+
+func TestSlowRequestDetect(t *testing.T) {
+	writer := &testRequestLogWriter{}
+	appRequestLogWriter = writer
+	warnings := func() []string {
+		var entries []string
+		for _, entry := range writer.entries {
+			if strings.HasPrefix(entry, "WARN ") {
+				entries = append(entries, entry)
+			}
+		}
+		return entries
+	}
+	defer func() {
+		appRequestLogWriter = &stdRequestLogWriter{}
+		appSlowRequestThreshold, appSlowRequestPublishEvent = 0, false
+	}()
+
+	oldStack := mwStack
+	mwStack = []MiddlewareFunc{interceptorMiddleware, actionMiddleware}
+	invalidateMwChain()
+	defer func() {
+		mwStack = oldStack
+		invalidateMwChain()
+	}()
+
+	appConfig, _ = config.ParseString("")
+	err := initSecurity(filepath.Join(getTestdataPath(), appConfigDir()), appConfig)
+	assert.FailNowOnError(t, err, "")
+	e := newEngine(appConfig)
+
+	events := make(chan *SlowRequest, 1)
+	onSlowRequest := func(e *Event) { events <- e.Data.(*SlowRequest) }
+	SubscribeEventf(EventOnSlowRequest, onSlowRequest)
+	defer UnsubscribeEventf(EventOnSlowRequest, onSlowRequest)
+
+	newCtx := func() *Context {
+		r := httptest.NewRequest("GET", "http://localhost:8080/credits", nil)
+		r.Header.Set("X-Request-Id", "5946ed129bf23409520736de")
+		ctx := e.prepareContext(httptest.NewRecorder(), r)
+		ctx.route = &router.Route{Name: "credits"}
+		ctx.controller, ctx.action = "Site", &MethodInfo{Name: "Credits"}
+		ctx.target = &Site{Context: ctx}
+		e.executeMiddlewares(ctx)
+		e.writeReply(ctx)
+		return ctx
+	}
+
+	// detector disabled
+	ctx := newCtx()
+	checkSlowRequest(ctx)
+	assert.Equal(t, [stageCount]time.Duration{}, ctx.stages)
+	assert.Equal(t, 0, len(warnings()))
+
+	// under threshold
+	appSlowRequestThreshold = time.Hour
+	checkSlowRequest(newCtx())
+	assert.Equal(t, 0, len(warnings()))
+
+	// slow request
+	appSlowRequestThreshold, appSlowRequestPublishEvent = time.Nanosecond, true
+	ctx = newCtx()
+	for _, stage := range []int{stageInterceptors, stageAction, stageRender, stageWrite} {
+		assert.True(t, ctx.stages[stage] > 0)
+	}
+	checkSlowRequest(ctx)
+
+	var sr *SlowRequest
+	select {
+	case sr = <-events:
+	case <-time.After(time.Second):
+		t.Fatal("OnSlowRequest event is not published")
+	}
+	assert.Equal(t, "5946ed129bf23409520736de", sr.RequestID)
+	assert.Equal(t, "credits", sr.Route)
+	assert.Equal(t, "GET", sr.Method)
+	assert.Equal(t, "/credits", sr.Path)
+	assert.Equal(t, time.Nanosecond, sr.Threshold)
+	assert.True(t, sr.Total > 0)
+	assert.Equal(t, stageCount, len(sr.Stages))
+	assert.Equal(t, "action", sr.Stages[stageAction].Name)
+	assert.Equal(t, ctx.stages[stageAction], sr.Stages[stageAction].Duration)
+
+	assert.Equal(t, 1, len(warnings()))
+	entry := warnings()[0]
+	assert.True(t, strings.HasPrefix(entry, "WARN reqid=5946ed129bf23409520736de route=credits action=Site.Credits "))
+	assert.True(t, strings.Contains(entry, "Slow request: GET /credits took "))
+	assert.True(t, strings.Contains(entry, " routing=0s session=0s params=0s interceptors="))
+
+	// released context
+	ctx.Reset()
+	assert.Equal(t, [stageCount]time.Duration{}, ctx.stages)
+}
+
+func TestSlowRequestTimeout(t *testing.T) {
+	defer func() { appSlowRequestThreshold, appSlowRequestPublishEvent = 0, false }()
+	appSlowRequestThreshold, appSlowRequestPublishEvent = 10*time.Millisecond, true
+
+	appConfig, _ = config.ParseString("")
+	err := initSecurity(filepath.Join(getTestdataPath(), appConfigDir()), appConfig)
+	assert.FailNowOnError(t, err, "")
+	e := newEngine(appConfig)
+	defer invalidateMwChain()
+
+	events := make(chan *SlowRequest, 1)
+	onSlowRequest := func(e *Event) { events <- e.Data.(*SlowRequest) }
+	SubscribeEventf(EventOnSlowRequest, onSlowRequest)
+	defer UnsubscribeEventf(EventOnSlowRequest, onSlowRequest)
+
+	exporter := NewInMemorySpanExporter()
+	appTracingEnabled, appSpanExporter = true, exporter
+	defer func() { appTracingEnabled, appSpanExporter = false, nil }()
+
+	// timed out request is checked when the action returns, response
+	// writer is not touched by then
+	mwChain = []*Middleware{{next: func(ctx *Context, m *Middleware) {
+		<-ctx.Context().Done()
+		time.Sleep(10 * time.Millisecond)
+		ctx.Res.Header().Set("X-Report", "late")
+	}, further: &Middleware{}}}
+	w := httptest.NewRecorder()
+	ctx := e.prepareContext(w, httptest.NewRequest("GET", "http://localhost:8080/reports", nil))
+	startRequestSpan(ctx)
+	assert.False(t, e.executeMiddlewaresWithTimeout(ctx, 20*time.Millisecond))
+
+	select {
+	case sr := <-events:
+		assert.Equal(t, "/reports", sr.Path)
+		assert.True(t, sr.Total > 20*time.Millisecond)
+	case <-time.After(time.Second):
+		t.Fatal("OnSlowRequest event is not published for timed out request")
+	}
+
+	assert.Equal(t, 503, w.Code)
+	assert.Equal(t, "", w.Header().Get("X-Report"))
+	for _, span := range exporter.Spans() {
+		if span.Name == "ServeHTTP" {
+			assert.Equal(t, "503", span.Attributes["http.status_code"])
+		}
+	}
+}
@@ -0,0 +1,262 @@
+// Copyright (c) Jeevanandam M. (https://github.com/jeevatkm)
+// go-aah/aah source code and usage is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package aah
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"aahframework.org/essentials.v0"
+	"aahframework.org/log.v0"
+)
+
+const (
+	contentTypeEventStream = "text/event-stream; charset=utf-8"
+	headerLastEventID      = "Last-Event-ID"
+	headerCacheControl     = "Cache-Control"
+	headerXAccelBuffering  = "X-Accel-Buffering"
+)
+
+var errEventStreamClosed = errors.New("sse: event stream is closed")
+
+type (
+	// SSEvent is a Server-Sent Event, refer to
+	// https://html.spec.whatwg.org/multipage/server-sent-events.html.
+	// Data value string and []byte are written as-is, rest of the types are
+	// marshalled as JSON.
+	SSEvent struct {
+		ID    string
+		Event string
+		Data  interface{}
+		Retry time.Duration
+	}
+
+	// SSEHandler func type is Server-Sent Events handler signature, events are
+	// sent to client via given event stream until handler returns.
+	SSEHandler func(stream *EventStream)
+
+	// SSE renders the Server-Sent Events into response, each event is flushed
+	// to the client immediately. Keep-alive comment is sent on every
+	// `KeepAlive` interval if it's greater than zero.
+	SSE struct {
+		Handler   SSEHandler
+		KeepAlive time.Duration
+
+		done        <-chan struct{}
+		lastEventID string
+	}
+
+	// EventStream is used to send Server-Sent Events to the client. It's safe
+	// for concurrent use.
+	EventStream struct {
+		mu          sync.Mutex
+		w           io.Writer
+		flusher     http.Flusher
+		done        <-chan struct{}
+		lastEventID string
+		err         error
+	}
+)
+
+//‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾
+// SSE Render methods
+//___________________________________
+
+// Render method writes the Server-Sent Events into HTTP response.
+func (s *SSE) Render(w io.Writer) error {
+	if s.Handler == nil {
+		return errors.New("sse: handler is nil")
+	}
+
+	stream := &EventStream{w: w, done: s.done, lastEventID: s.lastEventID}
+	stream.flusher, _ = w.(http.Flusher)
+
+	stop, wg := make(chan struct{}), sync.WaitGroup{}
+	if s.KeepAlive > 0 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			stream.keepAlive(s.KeepAlive, stop)
+		}()
+	}
+
+	s.Handler(stream)
+	close(stop)
+	wg.Wait()
+
+	if stream.err == errEventStreamClosed {
+		return nil
+	}
+	return stream.err
+}
+
+//‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾
+// EventStream methods
+//___________________________________
+
+// Send method writes the given event to the client and flushes it. It returns
+// an error if client is disconnected or write fails, stop sending on error.
+// Event `ID` and `Event` must not contain CR or LF, it would inject the
+// event fields; multi-line `Data` is written as multiple data lines.
+func (es *EventStream) Send(event *SSEvent) error {
+	if strings.ContainsAny(event.ID, "\r\n") {
+		return errors.New("sse: event id must not contain CR or LF")
+	}
+
+	if strings.ContainsAny(event.Event, "\r\n") {
+		return errors.New("sse: event name must not contain CR or LF")
+	}
+
+	buf := &bytes.Buffer{}
+	if !ess.IsStrEmpty(event.ID) {
+		fmt.Fprintf(buf, "id: %s\n", event.ID)
+	}
+
+	if !ess.IsStrEmpty(event.Event) {
+		fmt.Fprintf(buf, "event: %s\n", event.Event)
+	}
+
+	if event.Retry > 0 {
+		fmt.Fprintf(buf, "retry: %d\n", event.Retry/time.Millisecond)
+	}
+
+	var data string
+	switch v := event.Data.(type) {
+	case nil:
+	case string:
+		data = v
+	case []byte:
+		data = string(v)
+	default:
+		b, err := json.Marshal(v)
+		if err != nil {
+			return err
+		}
+		data = string(b)
+	}
+
+	data = strings.Replace(strings.Replace(data, "\r\n", "\n", -1), "\r", "\n", -1)
+	for _, line := range strings.Split(data, "\n") {
+		fmt.Fprintf(buf, "data: %s\n", line)
+	}
+	buf.WriteByte('\n')
+
+	return es.write(buf.Bytes())
+}
+
+// Comment method writes the given comment to the client, it's ignored by
+// the client. For e.g.: used as keep-alive.
+func (es *EventStream) Comment(comment string) error {
+	return es.write([]byte(": " + comment + "\n\n"))
+}
+
+// LastEventID method returns the value of request header `Last-Event-ID`, it's
+// sent by client on reconnect to resume the events.
+func (es *EventStream) LastEventID() string {
+	return es.lastEventID
+}
+
+// Done method returns a channel that's closed when the client is
+// disconnected.
+func (es *EventStream) Done() <-chan struct{} {
+	return es.done
+}
+
+func (es *EventStream) write(b []byte) error {
+	es.mu.Lock()
+	defer es.mu.Unlock()
+
+	if es.err != nil {
+		return es.err
+	}
+
+	select {
+	case <-es.done:
+		es.err = errEventStreamClosed
+		return es.err
+	default:
+	}
+
+	if _, err := es.w.Write(b); err != nil {
+		es.err = err
+		return err
+	}
+
+	if es.flusher != nil {
+		es.flusher.Flush()
+	}
+	return nil
+}
+
+func (es *EventStream) keepAlive(interval time.Duration, stop <-chan struct{}) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			if err := es.Comment("keep-alive"); err != nil {
+				return
+			}
+		case <-es.done:
+			return
+		case <-stop:
+			return
+		}
+	}
+}
+
+//‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾
+// Unexported methods
+//___________________________________
+
+// writeEventStream method writes the Server-Sent Events on the wire without
+// buffering and gzip. Stream is stopped when client disconnects.
+func (e *engine) writeEventStream(ctx *Context, sse *SSE) {
+	reply := ctx.Reply()
+	reply.Header(headerCacheControl, "no-cache")
+	reply.Header(headerXAccelBuffering, "no")
+
+	sse.done = ctx.Req.Raw.Context().Done()
+	sse.lastEventID = ctx.Req.Header.Get(headerLastEventID)
+
+	// 'OnPreReply' server extension point
+	publishOnPreReplyEvent(ctx)
+
+	// HTTP headers, cookies and status
+	e.writeHeaders(ctx)
+	e.setCookies(ctx)
+	ctx.Res.WriteHeader(reply.Code)
+	if flusher, ok := ctx.Res.(http.Flusher); ok {
+		flusher.Flush()
+	}
+
+	// Response is being written on the wire
+	reply.Done()
+	if err := sse.Render(ctx.Res); err != nil {
+		ctx.Log().Errorf("sse: %s on %s", err, ctx.Req.Path)
+	}
+
+	// 'OnAfterReply' server extension point
+	publishOnAfterReplyEvent(ctx)
+}
+
+// sseKeepAlive method returns the keep-alive interval of Server-Sent Events
+// from aah.conf `render.sse.keep_alive`.
+func sseKeepAlive() time.Duration {
+	keepAlive, err := time.ParseDuration(AppConfig().StringDefault("render.sse.keep_alive", "15s"))
+	if err != nil {
+		log.Errorf("'render.sse.keep_alive': %s", err)
+		return 15 * time.Second
+	}
+	return keepAlive
+}
@@ -0,0 +1,101 @@
+// Copyright (c) Jeevanandam M. (https://github.com/jeevatkm)
+// go-aah/aah source code and usage is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package aah
+
+import (
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"aahframework.org/ahttp.v0"
+	"aahframework.org/config.v0"
+	"aahframework.org/test.v0/assert"
+)
+
+func TestSSERender(t *testing.T) {
+	w := httptest.NewRecorder()
+	sse := &SSE{Handler: func(stream *EventStream) {
+		assert.Nil(t, stream.Send(&SSEvent{ID: "1", Event: "greet", Data: "hello\nworld", Retry: 3 * time.Second}))
+		assert.Nil(t, stream.Send(&SSEvent{Data: Data{"count": 10}}))
+		assert.Nil(t, stream.Comment("ping"))
+	}}
+
+	err := sse.Render(w)
+	assert.Nil(t, err)
+	assert.True(t, w.Flushed)
+	assert.Equal(t, "id: 1\nevent: greet\nretry: 3000\ndata: hello\ndata: world\n\n"+
+		"data: {\"count\":10}\n\n"+
+		": ping\n\n", w.Body.String())
+
+	err = (&SSE{}).Render(w)
+	assert.Equal(t, "sse: handler is nil", err.Error())
+
+	// CR and LF
+	w = httptest.NewRecorder()
+	sse = &SSE{Handler: func(stream *EventStream) {
+		assert.Equal(t, "sse: event id must not contain CR or LF", stream.Send(&SSEvent{ID: "1\ndata: forged"}).Error())
+		assert.Equal(t, "sse: event name must not contain CR or LF", stream.Send(&SSEvent{Event: "greet\r"}).Error())
+		assert.Nil(t, stream.Send(&SSEvent{Data: "hello\r\nworld\rbye"}))
+	}}
+	assert.Nil(t, sse.Render(w))
+	assert.Equal(t, "data: hello\ndata: world\ndata: bye\n\n", w.Body.String())
+}
+
+func TestSSEClientDisconnect(t *testing.T) {
+	done := make(chan struct{})
+	w := httptest.NewRecorder()
+	sse := &SSE{done: done, Handler: func(stream *EventStream) {
+		assert.Nil(t, stream.Send(&SSEvent{Data: "first"}))
+		close(done)
+
+		<-stream.Done()
+		assert.Equal(t, errEventStreamClosed, stream.Send(&SSEvent{Data: "second"}))
+	}}
+
+	err := sse.Render(w)
+	assert.Nil(t, err)
+	assert.Equal(t, "data: first\n\n", w.Body.String())
+}
+
+func TestSSEKeepAlive(t *testing.T) {
+	w := httptest.NewRecorder()
+	sse := &SSE{KeepAlive: 10 * time.Millisecond, Handler: func(stream *EventStream) {
+		time.Sleep(35 * time.Millisecond)
+	}}
+
+	err := sse.Render(w)
+	assert.Nil(t, err)
+	assert.True(t, strings.HasPrefix(w.Body.String(), ": keep-alive\n\n"))
+}
+
+func TestSSEWriteReply(t *testing.T) {
+	appConfig, _ = config.ParseString("")
+	err := initSecurity(filepath.Join(getTestdataPath(), appConfigDir()), appConfig)
+	assert.FailNowOnError(t, err, "")
+	e := newEngine(appConfig)
+
+	req := httptest.NewRequest("GET", "http://localhost:8080/dashboard/live", nil)
+	req.Header.Set(headerLastEventID, "41")
+	w := httptest.NewRecorder()
+	ctx := e.prepareContext(w, req)
+
+	ctx.Reply().SSE(func(stream *EventStream) {
+		assert.Equal(t, "41", stream.LastEventID())
+		_ = stream.Send(&SSEvent{ID: "42", Data: "resumed"})
+	})
+	assert.Equal(t, 15*time.Second, ctx.Reply().Rdr.(*SSE).KeepAlive)
+	assert.False(t, ctx.Reply().gzip)
+
+	e.writeReply(ctx)
+	assert.True(t, ctx.Reply().done)
+
+	resp := w.Result()
+	assert.Equal(t, 200, resp.StatusCode)
+	assert.Equal(t, contentTypeEventStream, resp.Header.Get(ahttp.HeaderContentType))
+	assert.Equal(t, "no-cache", resp.Header.Get(headerCacheControl))
+	assert.Equal(t, "id: 42\ndata: resumed\n\n", w.Body.String())
+}
@@ -16,7 +16,6 @@ import (
 
 	"aahframework.org/ahttp.v0"
 	"aahframework.org/essentials.v0"
-	"aahframework.org/log.v0"
 )
 
 const dirStatic = "static"
@@ -30,30 +29,37 @@ func (e *engine) serveStatic(ctx *Context) error {
 	//   httpDir -> value is from routes config
 	//   filePath -> value is from request
 	httpDir, filePath := getHTTPDirAndFilePath(ctx)
-	log.Tracef("Dir: %s, Filepath: %s", httpDir, filePath)
+	ctx.Log().Tracef("Dir: %s, Filepath: %s", httpDir, filePath)
 
 	res, req := ctx.Res, ctx.Req
 	f, err := httpDir.Open(filePath)
 	if err != nil {
 		if os.IsNotExist(err) {
-			log.Errorf("file not found: %s", req.Path)
+			ctx.Log().Errorf("file not found: %s", req.Path)
 			return errFileNotFound
 		} else if os.IsPermission(err) {
-			log.Warnf("permission issue: %s", req.Path)
-			res.WriteHeader(http.StatusForbidden)
-			fmt.Fprintf(res, "403 Forbidden")
+			ctx.Log().Warnf("permission issue: %s", req.Path)
+			handleError(ctx, &HTTPError{Code: http.StatusForbidden, Err: err})
 		} else {
-			res.WriteHeader(http.StatusInternalServerError)
-			fmt.Fprintf(res, "500 Internal Server Error")
+			handleError(ctx, &HTTPError{Code: http.StatusInternalServerError, Err: err})
 		}
+		e.writeReply(ctx)
 		return nil
 	}
 
 	defer ess.CloseQuietly(f)
 	fi, err := f.Stat()
 	if err != nil {
-		res.WriteHeader(http.StatusInternalServerError)
-		fmt.Fprintf(res, "500 Internal Server Error")
+		handleError(ctx, &HTTPError{Code: http.StatusInternalServerError, Err: err})
+		e.writeReply(ctx)
+		return nil
+	}
+
+	// Directory listing is not allowed
+	if !fi.Mode().IsRegular() && !(fi.Mode().IsDir() && ctx.route.ListDir) {
+		ctx.Log().Warnf("directory listing not allowed: %s", req.Path)
+		handleError(ctx, &HTTPError{Code: http.StatusForbidden, Message: "Directory listing not allowed"})
+		e.writeReply(ctx)
 		return nil
 	}
 
@@ -75,29 +81,23 @@ func (e *engine) serveStatic(ctx *Context) error {
 	}
 
 	// Serve directory
-	if fi.Mode().IsDir() && ctx.route.ListDir {
-		// redirect if the directory name doesn't end in a slash
-		if req.Path[len(req.Path)-1] != '/' {
-			log.Debugf("redirecting to dir: %s", req.Path+"/")
-			http.Redirect(res, req.Raw, path.Base(req.Path)+"/", http.StatusFound)
-			return nil
-		}
-
-		// 'OnPreReply' server extension point
-		publishOnPreReplyEvent(ctx)
-
-		directoryList(res, req.Raw, f)
+	// redirect if the directory name doesn't end in a slash
+	if req.Path[len(req.Path)-1] != '/' {
+		ctx.Log().Debugf("redirecting to dir: %s", req.Path+"/")
+		http.Redirect(res, req.Raw, path.Base(req.Path)+"/", http.StatusFound)
 
-		// 'OnAfterReply' server extension point
-		publishOnAfterReplyEvent(ctx)
+		// Request access log
+		sendToAccessLog(ctx)
 		return nil
 	}
 
-	// Flow reached here it means directory listing is not allowed
-	log.Warnf("directory listing not allowed: %s", req.Path)
-	res.WriteHeader(http.StatusForbidden)
-	fmt.Fprintf(res, "403 Directory listing not allowed")
+	// 'OnPreReply' server extension point
+	publishOnPreReplyEvent(ctx)
+
+	directoryList(res, req.Raw, f)
 
+	// 'OnAfterReply' server extension point
+	publishOnAfterReplyEvent(ctx)
 	return nil
 }
 
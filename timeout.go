@@ -0,0 +1,306 @@
+// Copyright (c) Jeevanandam M. (https://github.com/jeevatkm)
+// go-aah/aah source code and usage is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package aah
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+
+	"aahframework.org/ahttp.v0"
+	"aahframework.org/config.v0"
+)
+
+var (
+	appRequestTimeout       time.Duration
+	appRequestTimeoutStatus = http.StatusServiceUnavailable
+	appRouteTimeouts        = make(map[string]time.Duration)
+
+	errRequestTimeout = errors.New("request handling timed out")
+)
+
+// timeoutWriter guards the response writer of the request handling which has
+// timeout. Headers are buffered until first write and writes are discarded
+// once the request handling is timed out.
+type timeoutWriter struct {
+	ahttp.ResponseWriter
+	mu          sync.Mutex
+	hdr         http.Header
+	replied     chan struct{}
+	timedOut    bool
+	finished    bool
+	wroteHeader bool
+	status      int
+}
+
+//‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾
+// timeoutWriter methods
+//___________________________________
+
+// Header method returns the buffered response headers.
+func (tw *timeoutWriter) Header() http.Header {
+	return tw.hdr
+}
+
+// Write method writes the given bytes unless request handling is timed out.
+func (tw *timeoutWriter) Write(b []byte) (int, error) {
+	tw.mu.Lock()
+	defer tw.mu.Unlock()
+	if tw.timedOut {
+		return 0, http.ErrHandlerTimeout
+	}
+
+	tw.writeHeader(http.StatusOK)
+	return tw.ResponseWriter.Write(b)
+}
+
+// WriteHeader method writes the given status code unless request handling is
+// timed out.
+func (tw *timeoutWriter) WriteHeader(code int) {
+	tw.mu.Lock()
+	defer tw.mu.Unlock()
+	if !tw.timedOut {
+		tw.writeHeader(code)
+	}
+}
+
+// writeHeader method copies the buffered headers and writes the status code
+// once, caller must hold the lock.
+func (tw *timeoutWriter) writeHeader(code int) {
+	if tw.wroteHeader {
+		return
+	}
+
+	tw.wroteHeader = true
+	dst := tw.ResponseWriter.Header()
+	for k, v := range tw.hdr {
+		dst[k] = v
+	}
+	tw.ResponseWriter.WriteHeader(code)
+}
+
+// finish method marks the request handling as finished, it returns false if
+// it's already timed out.
+func (tw *timeoutWriter) finish() bool {
+	tw.mu.Lock()
+	defer tw.mu.Unlock()
+	tw.finished = !tw.timedOut
+	return tw.finished
+}
+
+// timeout method marks the request handling as timed out, it returns false
+// if it's already finished.
+func (tw *timeoutWriter) timeout() bool {
+	tw.mu.Lock()
+	defer tw.mu.Unlock()
+	tw.timedOut = !tw.finished
+	return tw.timedOut
+}
+
+// isTimedOut method returns true if the request handling is timed out.
+func (tw *timeoutWriter) isTimedOut() bool {
+	tw.mu.Lock()
+	defer tw.mu.Unlock()
+	return tw.timedOut
+}
+
+// isHeaderWritten method returns true if the status is written on the wire.
+func (tw *timeoutWriter) isHeaderWritten() bool {
+	tw.mu.Lock()
+	defer tw.mu.Unlock()
+	return tw.wroteHeader
+}
+
+// unwrap method returns the underlying response writer with buffered
+// headers, it's called after request handling finished.
+func (tw *timeoutWriter) unwrap() ahttp.ResponseWriter {
+	if !tw.wroteHeader {
+		dst := tw.ResponseWriter.Header()
+		for k, v := range tw.hdr {
+			dst[k] = v
+		}
+	}
+	return tw.ResponseWriter
+}
+
+//‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾
+// Unexported methods
+//___________________________________
+
+// executeMiddlewaresWithTimeout method executes the middlewares, interceptors
+// and action on separate goroutine with request context deadline. It returns
+// true if it's finished within timeout. Otherwise it replies timeout status
+// via error handler and returns false, then pooled context is released by
+// the goroutine when the action returns. Client disconnect cancels the
+// request context, however it's not a timeout.
+func (e *engine) executeMiddlewaresWithTimeout(ctx *Context, timeout time.Duration) bool {
+	raw := ctx.Req.Raw
+	c, cancel := context.WithTimeout(raw.Context(), timeout)
+	ctx.Req.Raw = raw.WithContext(c)
+
+	// timeout reply has its own request, since request context is in use
+	// by the goroutine
+	treq := raw.WithContext(raw.Context())
+	treq.Header = cloneHeader(raw.Header)
+	treq.MultipartForm = nil
+	locale := ctx.Req.Locale
+
+	tw := &timeoutWriter{
+		ResponseWriter: ctx.Res,
+		hdr:            cloneHeader(ctx.Res.Header()),
+		replied:        make(chan struct{}),
+	}
+	ctx.Res = tw
+
+	done := make(chan interface{}, 1)
+	go func() {
+		defer func() {
+			r := recover()
+			if tw.finish() {
+				done <- r
+				return
+			}
+
+			// wait for the timeout reply, then release the context. Response
+			// writer belongs to the finished handler, so it's not touched;
+			// root span is ended with the status written on the wire.
+			<-tw.replied
+			if r != nil {
+				ctx.Log().Errorf("Panic on timed out request %s: %v", ctx.Req.Path, r)
+			}
+			ctx.Res = nil
+			ctx.Reply().Status(tw.status)
+			endRequestSpan(ctx)
+			checkSlowRequest(ctx)
+			e.putContext(ctx)
+		}()
+
+		e.executeMiddlewares(ctx)
+	}()
+
+	select {
+	case r := <-done:
+		return e.finishWithTimeout(ctx, tw, raw, cancel, r)
+	case <-c.Done():
+		if c.Err() != context.DeadlineExceeded {
+			// client disconnected, it's not a timeout
+			deadline, _ := c.Deadline()
+			select {
+			case r := <-done:
+				return e.finishWithTimeout(ctx, tw, raw, cancel, r)
+			case <-time.After(time.Until(deadline)):
+			}
+		}
+
+		if !tw.timeout() { // finished meanwhile
+			return e.finishWithTimeout(ctx, tw, raw, cancel, <-done)
+		}
+	}
+
+	defer close(tw.replied)
+	cancel()
+	e.writeTimeoutReply(ctx, tw, treq, locale, timeout)
+	return false
+}
+
+// finishWithTimeout method restores the request and response writer of the
+// finished request handling, so the reply is written with request context
+// which is not cancelled. Panic of the goroutine is re-raised for recovery
+// handling.
+func (e *engine) finishWithTimeout(ctx *Context, tw *timeoutWriter, raw *http.Request, cancel context.CancelFunc, r interface{}) bool {
+	ctx.Req.Raw = raw
+	cancel()
+	ctx.Res = tw.unwrap()
+	if r != nil {
+		panic(r)
+	}
+	return true
+}
+
+// writeTimeoutReply method logs the timeout and replies the timeout status
+// via error handler, unless the status is already written, on separate
+// context with its own request, since request context is still in use by
+// the goroutine. Status on the wire is captured for the goroutine.
+func (e *engine) writeTimeoutReply(ctx *Context, tw *timeoutWriter, req *http.Request, locale *ahttp.Locale, timeout time.Duration) {
+	tctx := e.getContext()
+	tctx.Req, tctx.Res = ahttp.ParseRequest(req, e.getRequest()), tw.ResponseWriter
+	tctx.Req.Locale = locale
+	tctx.domain, tctx.route = ctx.domain, ctx.route
+	tctx.controller, tctx.action = ctx.controller, ctx.action
+	tctx.startTime = ctx.startTime
+	tctx.reply = e.getReply()
+	tctx.viewArgs = make(map[string]interface{})
+	tctx.Log().Warnf("Request timed out on %s after %s", req.URL.Path, timeout)
+
+	if !tw.isHeaderWritten() {
+		if e.isRequestIDEnabled {
+			tctx.Reply().Header(e.requestIDHeader, req.Header.Get(e.requestIDHeader))
+		}
+		tctx.Reply().DisableGzip()
+
+		handleError(tctx, &HTTPError{Code: appRequestTimeoutStatus, Err: errRequestTimeout})
+		e.writeReply(tctx)
+		metricsObserveRequest(tctx)
+	}
+	tw.status = tw.ResponseWriter.Status()
+
+	// response writer is released along with request context
+	tctx.Res = nil
+	e.putContext(tctx)
+}
+
+// routeTimeout method returns the request handling timeout of the route
+// `timeout` from routes.conf otherwise `request.timeout` from aah.conf.
+func routeTimeout(ctx *Context) time.Duration {
+	if timeout, found := appRouteTimeouts[routeConfigPath(ctx)]; found {
+		return timeout
+	}
+	return appRequestTimeout
+}
+
+// cloneHeader method returns the copy of given HTTP header.
+func cloneHeader(hdr http.Header) http.Header {
+	clone := make(http.Header, len(hdr))
+	for k, v := range hdr {
+		clone[k] = append([]string(nil), v...)
+	}
+	return clone
+}
+
+// initRequestTimeout method initializes the request handling timeout from
+// aah.conf `request.timeout` and routes.conf `timeout`.
+func initRequestTimeout(appCfg *config.Config) error {
+	timeout, err := time.ParseDuration(appCfg.StringDefault("request.timeout", "0s"))
+	if err != nil {
+		return fmt.Errorf("'request.timeout' value is not a valid time unit: %s", err)
+	}
+	appRequestTimeout = timeout
+
+	status := appCfg.IntDefault("request.timeout_status", http.StatusServiceUnavailable)
+	if status != http.StatusServiceUnavailable && status != http.StatusGatewayTimeout {
+		return fmt.Errorf("'request.timeout_status' value '%d' is not supported", status)
+	}
+	appRequestTimeoutStatus = status
+
+	appRouteTimeouts = make(map[string]time.Duration)
+	for _, path := range appRouteConfigPaths {
+		key := path + ".timeout"
+		value, found := appRoutesConfig.String(key)
+		if !found {
+			continue
+		}
+
+		duration, err := time.ParseDuration(value)
+		if err != nil {
+			return fmt.Errorf("'%s' value is not a valid time unit: %s", key, err)
+		}
+		appRouteTimeouts[path] = duration
+	}
+
+	return nil
+}
@@ -0,0 +1,149 @@
+// Copyright (c) Jeevanandam M. (https://github.com/jeevatkm)
+// go-aah/aah source code and usage is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package aah
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"aahframework.org/ahttp.v0"
+	"aahframework.org/config.v0"
+	"aahframework.org/router.v0"
+	"aahframework.org/test.v0/assert"
+)
+
+func TestTimeoutInit(t *testing.T) {
+	defer func() {
+		appRoutesConfig, appRouteConfigPaths = nil, nil
+		appRequestTimeout, appRequestTimeoutStatus = 0, http.StatusServiceUnavailable
+		appRouteTimeouts = make(map[string]time.Duration)
+	}()
+
+	appRoutesConfig, _ = config.ParseString("")
+	appRoutesConfig.SetString("domains.localhost.routes.reports.timeout", "1m")
+	appRoutesConfig.SetString("domains.localhost.routes.upload.timeout", "0s")
+	appRouteConfigPaths = map[string]string{
+		routeConfigKey("localhost", "reports"): "domains.localhost.routes.reports",
+		routeConfigKey("localhost", "upload"):  "domains.localhost.routes.upload",
+		routeConfigKey("localhost", "users"):   "domains.localhost.routes.users",
+	}
+
+	cfg, _ := config.ParseString("")
+	assert.Nil(t, initRequestTimeout(cfg))
+	assert.Equal(t, time.Duration(0), appRequestTimeout)
+	assert.Equal(t, http.StatusServiceUnavailable, appRequestTimeoutStatus)
+
+	cfg.SetString("request.timeout", "10s")
+	cfg.SetInt("request.timeout_status", http.StatusGatewayTimeout)
+	assert.Nil(t, initRequestTimeout(cfg))
+	assert.Equal(t, http.StatusGatewayTimeout, appRequestTimeoutStatus)
+
+	routeCtx := func(name string) *Context {
+		return &Context{domain: &router.Domain{Host: "localhost"}, route: &router.Route{Name: name}}
+	}
+	assert.Equal(t, time.Minute, routeTimeout(routeCtx("reports")))
+	assert.Equal(t, time.Duration(0), routeTimeout(routeCtx("upload")))
+	assert.Equal(t, 10*time.Second, routeTimeout(routeCtx("users")))
+
+	// errors
+	cfg.SetInt("request.timeout_status", http.StatusRequestTimeout)
+	assert.Equal(t, "'request.timeout_status' value '408' is not supported", initRequestTimeout(cfg).Error())
+
+	cfg.SetString("request.timeout", "10 seconds")
+	assert.NotNil(t, initRequestTimeout(cfg))
+
+	cfg.SetString("request.timeout", "10s")
+	cfg.SetInt("request.timeout_status", http.StatusServiceUnavailable)
+	appRoutesConfig.SetString("domains.localhost.routes.reports.timeout", "1 minute")
+	assert.NotNil(t, initRequestTimeout(cfg))
+}
+
+func TestTimeoutExecuteMiddlewares(t *testing.T) {
+	appConfig, _ = config.ParseString("")
+	err := initSecurity(filepath.Join(getTestdataPath(), appConfigDir()), appConfig)
+	assert.FailNowOnError(t, err, "")
+	e := newEngine(appConfig)
+	defer invalidateMwChain()
+
+	parent := context.Background()
+	execute := func(action func(ctx *Context)) (*Context, *httptest.ResponseRecorder, bool) {
+		mwChain = []*Middleware{{next: func(ctx *Context, m *Middleware) { action(ctx) }, further: &Middleware{}}}
+		req := httptest.NewRequest("GET", "http://localhost:8080/reports", nil).WithContext(parent)
+		w := httptest.NewRecorder()
+		ctx := e.prepareContext(w, req)
+		return ctx, w, e.executeMiddlewaresWithTimeout(ctx, 20*time.Millisecond)
+	}
+
+	// finished within timeout
+	ctx, _, finished := execute(func(ctx *Context) {
+		_, hasDeadline := ctx.Context().Deadline()
+		assert.True(t, hasDeadline)
+		ctx.Res.Header().Set("X-Report", "daily")
+		ctx.Reply().Text("report")
+	})
+	assert.True(t, finished)
+	_, ok := ctx.Res.(*timeoutWriter)
+	assert.False(t, ok)
+	assert.Equal(t, "daily", ctx.Res.Header().Get("X-Report"))
+
+	// reply is written with request context which is not cancelled
+	assert.Nil(t, ctx.Context().Err())
+	_, hasDeadline := ctx.Context().Deadline()
+	assert.False(t, hasDeadline)
+
+	// client disconnect is not a timeout
+	var cancel context.CancelFunc
+	parent, cancel = context.WithCancel(context.Background())
+	_, w, finished := execute(func(ctx *Context) {
+		cancel()
+		<-ctx.Context().Done()
+		assert.Equal(t, context.Canceled, ctx.Context().Err())
+	})
+	assert.True(t, finished)
+	assert.Equal(t, http.StatusOK, w.Code)
+	parent = context.Background()
+
+	// timed out, context is not released until action returns
+	proceed, checked := make(chan struct{}), make(chan struct{})
+	_, w, finished = execute(func(ctx *Context) {
+		<-ctx.Context().Done()
+		<-proceed
+		assert.Equal(t, context.DeadlineExceeded, ctx.Context().Err())
+		assert.NotNil(t, ctx.Req)
+		ctx.Req.Header.Set("X-Report", "late")
+		_, err := ctx.Res.Write([]byte("late"))
+		assert.Equal(t, http.ErrHandlerTimeout, err)
+		close(checked)
+	})
+	close(proceed)
+	<-checked
+	assert.False(t, finished)
+	assert.Equal(t, http.StatusServiceUnavailable, w.Code)
+	assert.Equal(t, "503 Service Unavailable", w.Body.String())
+
+	// panic is re-raised for recovery handling
+	func() {
+		defer func() {
+			assert.Equal(t, "report failed", recover())
+		}()
+		_, _, _ = execute(func(ctx *Context) { panic("report failed") })
+	}()
+
+	// timeout status
+	appRequestTimeoutStatus = http.StatusGatewayTimeout
+	defer func() { appRequestTimeoutStatus = http.StatusServiceUnavailable }()
+	slept := make(chan struct{})
+	_, w, _ = execute(func(ctx *Context) {
+		time.Sleep(40 * time.Millisecond)
+		close(slept)
+	})
+	<-slept
+	assert.Equal(t, http.StatusGatewayTimeout, w.Code)
+	assert.Equal(t, ahttp.ContentTypePlainText.Raw(), w.Header().Get(ahttp.HeaderContentType))
+}
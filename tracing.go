@@ -0,0 +1,333 @@
+// Copyright (c) Jeevanandam M. (https://github.com/jeevatkm)
+// go-aah/aah source code and usage is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package aah
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"aahframework.org/config.v0"
+	"aahframework.org/essentials.v0"
+	"aahframework.org/log.v0"
+)
+
+const (
+	// HeaderTraceparent is W3C Trace Context header.
+	HeaderTraceparent = "Traceparent"
+
+	traceparentVersion      = "00"
+	traceparentFlagsSampled = "01"
+	traceExporterStdout     = "stdout"
+	traceRootSpanName       = "ServeHTTP"
+)
+
+var (
+	appTracingEnabled bool
+	appSpanExporter   SpanExporter
+)
+
+type (
+	// SpanExporter interface is to export the ended spans, register it via
+	// `aah.SetSpanExporter`.
+	SpanExporter interface {
+		ExportSpan(span *Span)
+	}
+
+	// Span is timed operation of the request pipeline. Span methods are no-op
+	// on nil span, i.e. tracing is disabled.
+	Span struct {
+		TraceID    string            `json:"trace_id"`
+		SpanID     string            `json:"span_id"`
+		ParentID   string            `json:"parent_id,omitempty"`
+		Name       string            `json:"name"`
+		StartTime  time.Time         `json:"start_time"`
+		EndTime    time.Time         `json:"end_time"`
+		Attributes map[string]string `json:"attributes,omitempty"`
+
+		// flags is trace flags of the incoming `traceparent`, it's
+		// propagated as-is.
+		flags string
+	}
+
+	// InMemorySpanExporter keeps the exported spans in memory, it's handy
+	// for tests and debugging. Spans are not evicted, so it's set only via
+	// `aah.SetSpanExporter`.
+	InMemorySpanExporter struct {
+		mu    sync.Mutex
+		spans []*Span
+	}
+
+	// StdoutSpanExporter writes the exported spans as JSON line into the
+	// writer, by default `os.Stdout`.
+	StdoutSpanExporter struct {
+		mu sync.Mutex
+		w  io.Writer
+	}
+)
+
+//‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾
+// Global methods
+//___________________________________
+
+// SetSpanExporter method sets the given span exporter for request tracing,
+// it takes precedence over aah.conf `server.tracing.exporter`. Tracing is
+// enabled via `server.tracing.enable`.
+//		For Example:
+//
+//		func init() {
+//			aah.SetSpanExporter(aah.NewInMemorySpanExporter())
+//		}
+func SetSpanExporter(exporter SpanExporter) {
+	appSpanExporter = exporter
+}
+
+// NewInMemorySpanExporter method returns the new in-memory span exporter.
+func NewInMemorySpanExporter() *InMemorySpanExporter {
+	return &InMemorySpanExporter{}
+}
+
+// NewStdoutSpanExporter method returns the new span exporter writes into
+// given writer, `os.Stdout` is used if it's nil.
+func NewStdoutSpanExporter(w io.Writer) *StdoutSpanExporter {
+	if w == nil {
+		w = os.Stdout
+	}
+	return &StdoutSpanExporter{w: w}
+}
+
+//‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾
+// Span methods
+//___________________________________
+
+// SetAttribute method sets the attribute of span.
+func (s *Span) SetAttribute(key, value string) {
+	if s == nil {
+		return
+	}
+
+	if s.Attributes == nil {
+		s.Attributes = make(map[string]string)
+	}
+	s.Attributes[key] = value
+}
+
+// End method ends the span and exports it, span is ended only once.
+func (s *Span) End() {
+	if s == nil || !s.EndTime.IsZero() {
+		return
+	}
+
+	s.EndTime = time.Now()
+	if appSpanExporter != nil {
+		appSpanExporter.ExportSpan(s)
+	}
+}
+
+// Duration method returns the span duration, it's zero until span ends.
+func (s *Span) Duration() time.Duration {
+	if s == nil || s.EndTime.IsZero() {
+		return 0
+	}
+	return s.EndTime.Sub(s.StartTime)
+}
+
+// Traceparent method returns the W3C `traceparent` header value of span.
+// Trace flags of the incoming `traceparent` are propagated, otherwise
+// span is sampled.
+func (s *Span) Traceparent() string {
+	if s == nil {
+		return ""
+	}
+
+	flags := s.flags
+	if ess.IsStrEmpty(flags) {
+		flags = traceparentFlagsSampled
+	}
+	return strings.Join([]string{traceparentVersion, s.TraceID, s.SpanID, flags}, "-")
+}
+
+// child method returns the new child span of the span.
+func (s *Span) child(name string) *Span {
+	return &Span{TraceID: s.TraceID, SpanID: newSpanID(), ParentID: s.SpanID, Name: name,
+		StartTime: time.Now(), flags: s.flags}
+}
+
+//‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾
+// InMemorySpanExporter methods
+//___________________________________
+
+// ExportSpan method keeps the span in memory.
+func (me *InMemorySpanExporter) ExportSpan(span *Span) {
+	me.mu.Lock()
+	defer me.mu.Unlock()
+	me.spans = append(me.spans, span)
+}
+
+// Spans method returns the exported spans in the order of span end.
+func (me *InMemorySpanExporter) Spans() []*Span {
+	me.mu.Lock()
+	defer me.mu.Unlock()
+	return append([]*Span(nil), me.spans...)
+}
+
+// Reset method clears the exported spans.
+func (me *InMemorySpanExporter) Reset() {
+	me.mu.Lock()
+	defer me.mu.Unlock()
+	me.spans = nil
+}
+
+//‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾
+// StdoutSpanExporter methods
+//___________________________________
+
+// ExportSpan method writes the span as JSON line.
+func (se *StdoutSpanExporter) ExportSpan(span *Span) {
+	b, err := json.Marshal(span)
+	if err != nil {
+		log.Error("Span export error: ", err)
+		return
+	}
+
+	se.mu.Lock()
+	defer se.mu.Unlock()
+	if _, err = se.w.Write(append(b, '\n')); err != nil {
+		log.Error("Span export error: ", err)
+	}
+}
+
+//‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾
+// Unexported methods
+//___________________________________
+
+// startRequestSpan method starts the root span of the request, trace is
+// continued from the request `traceparent` header if it's valid.
+func startRequestSpan(ctx *Context) {
+	if !appTracingEnabled {
+		return
+	}
+
+	span := &Span{SpanID: newSpanID(), Name: traceRootSpanName, StartTime: ctx.startTime}
+	if traceID, parentID, flags, ok := parseTraceparent(ctx.Req.Header.Get(HeaderTraceparent)); ok {
+		span.TraceID, span.ParentID, span.flags = traceID, parentID, flags
+	} else {
+		span.TraceID = newTraceID()
+	}
+	span.SetAttribute("http.method", ctx.Req.Method)
+	span.SetAttribute("http.path", ctx.Req.Path)
+	ctx.span, ctx.rootSpan = span, span
+}
+
+// endRequestSpan method ends the root span of the request with route and
+// response status.
+func endRequestSpan(ctx *Context) {
+	span := ctx.rootSpan
+	if span == nil {
+		return
+	}
+
+	if ctx.route != nil {
+		span.SetAttribute("aah.route", ctx.route.Name)
+	}
+
+	status := ctx.Reply().Code
+	if ctx.Res != nil && ctx.Res.Status() > 0 {
+		status = ctx.Res.Status()
+	}
+	span.SetAttribute("http.status_code", fmt.Sprintf("%d", status))
+	span.End()
+}
+
+// traceStage method starts the child span of the current span for the
+// request pipeline stage and sets it as current span. Returned func ends the
+// span and restores the parent span.
+//		For Example:
+//
+//		defer traceStage(ctx, mw.name)()
+func traceStage(ctx *Context, name string) func() {
+	parent := ctx.span
+	if parent == nil {
+		return noopTraceEnd
+	}
+
+	span := parent.child(name)
+	ctx.span = span
+	return func() {
+		span.End()
+		ctx.span = parent
+	}
+}
+
+func noopTraceEnd() {}
+
+// parseTraceparent method parses the W3C `traceparent` header value and
+// returns trace ID, parent span ID and trace flags.
+func parseTraceparent(value string) (string, string, string, bool) {
+	parts := strings.Split(strings.TrimSpace(value), "-")
+	if len(parts) < 4 || len(parts[0]) != 2 || parts[0] == "ff" ||
+		(parts[0] == traceparentVersion && len(parts) != 4) {
+		return "", "", "", false
+	}
+
+	traceID, parentID := parts[1], parts[2]
+	if !isTraceHex(parts[0]) || !isTraceHex(traceID) || len(traceID) != 32 ||
+		!isTraceHex(parentID) || len(parentID) != 16 ||
+		!isTraceHex(parts[3]) || len(parts[3]) != 2 ||
+		strings.Trim(traceID, "0") == "" || strings.Trim(parentID, "0") == "" {
+		return "", "", "", false
+	}
+	return traceID, parentID, parts[3], true
+}
+
+// isTraceHex method returns true if given value is lowercase hex.
+func isTraceHex(value string) bool {
+	for idx := 0; idx < len(value); idx++ {
+		c := value[idx]
+		if !(c >= '0' && c <= '9') && !(c >= 'a' && c <= 'f') {
+			return false
+		}
+	}
+	return len(value) > 0
+}
+
+func newTraceID() string {
+	return randomHex(16)
+}
+
+func newSpanID() string {
+	return randomHex(8)
+}
+
+func randomHex(n int) string {
+	b := make([]byte, n)
+	_, _ = rand.Read(b)
+	return hex.EncodeToString(b)
+}
+
+// initTracing method initializes the request tracing from aah.conf
+// `server.tracing`. Span exporter set via `aah.SetSpanExporter` is used if
+// present.
+func initTracing(appCfg *config.Config) error {
+	appTracingEnabled = appCfg.BoolDefault("server.tracing.enable", false)
+	if !appTracingEnabled || appSpanExporter != nil {
+		return nil
+	}
+
+	exporter := appCfg.StringDefault("server.tracing.exporter", traceExporterStdout)
+	switch exporter {
+	case traceExporterStdout:
+		appSpanExporter = NewStdoutSpanExporter(nil)
+	default:
+		return fmt.Errorf("'server.tracing.exporter' value '%s' is not supported", exporter)
+	}
+	return nil
+}
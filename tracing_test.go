@@ -0,0 +1,196 @@
+// Copyright (c) Jeevanandam M. (https://github.com/jeevatkm)
+// go-aah/aah source code and usage is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package aah
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+
+	"aahframework.org/config.v0"
+	"aahframework.org/router.v0"
+	"aahframework.org/test.v0/assert"
+)
+
+func testTraceMiddleware(ctx *Context, m *Middleware) {
+	span := ctx.StartSpan("db.query")
+	span.SetAttribute("db.table", "credits")
+	span.End()
+	m.Next(ctx)
+}
+
+func TestTracingTraceparent(t *testing.T) {
+	traceID, parentID, flags, ok := parseTraceparent("00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+	assert.True(t, ok)
+	assert.Equal(t, "4bf92f3577b34da6a3ce929d0e0e4736", traceID)
+	assert.Equal(t, "00f067aa0ba902b7", parentID)
+	assert.Equal(t, "01", flags)
+
+	// future version may have additional fields
+	_, _, _, ok = parseTraceparent("01-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01-extra")
+	assert.True(t, ok)
+
+	for _, value := range []string{
+		"",
+		"00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7",
+		"00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01-extra",
+		"ff-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01",
+		"00-4BF92F3577B34DA6A3CE929D0E0E4736-00f067aa0ba902b7-01",
+		"00-00000000000000000000000000000000-00f067aa0ba902b7-01",
+		"00-4bf92f3577b34da6a3ce929d0e0e4736-0000000000000000-01",
+		"00-4bf92f3577b34da6a3ce929d0e0e473-00f067aa0ba902b7-01",
+	} {
+		_, _, _, ok = parseTraceparent(value)
+		assert.False(t, ok)
+	}
+
+	span := &Span{TraceID: traceID, SpanID: parentID}
+	assert.Equal(t, "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01", span.Traceparent())
+
+	// incoming flags are propagated
+	span = &Span{TraceID: traceID, SpanID: parentID, flags: "00"}
+	assert.Equal(t, "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-00", span.Traceparent())
+	assert.Equal(t, "00", span.child("render").flags)
+	assert.Equal(t, 32, len(newTraceID()))
+	assert.Equal(t, 16, len(newSpanID()))
+
+	// nil span
+	var nilSpan *Span
+	nilSpan.SetAttribute("key", "value")
+	nilSpan.End()
+	assert.Equal(t, "", nilSpan.Traceparent())
+	assert.Equal(t, int64(0), int64(nilSpan.Duration()))
+}
+
+func TestTracingInit(t *testing.T) {
+	defer func() { appTracingEnabled, appSpanExporter = false, nil }()
+
+	cfg, _ := config.ParseString("")
+	assert.Nil(t, initTracing(cfg))
+	assert.False(t, appTracingEnabled)
+	assert.Nil(t, appSpanExporter)
+
+	cfg.SetBool("server.tracing.enable", true)
+	assert.Nil(t, initTracing(cfg))
+	_, ok := appSpanExporter.(*StdoutSpanExporter)
+	assert.True(t, ok)
+
+	// in-memory exporter is set only by application
+	appSpanExporter = nil
+	cfg.SetString("server.tracing.exporter", "memory")
+	assert.Equal(t, "'server.tracing.exporter' value 'memory' is not supported", initTracing(cfg).Error())
+
+	// exporter set by application
+	exporter := NewStdoutSpanExporter(nil)
+	SetSpanExporter(exporter)
+	assert.Nil(t, initTracing(cfg))
+	assert.Equal(t, exporter, appSpanExporter)
+
+	appSpanExporter = nil
+	cfg.SetString("server.tracing.exporter", "zipkin")
+	assert.Equal(t, "'server.tracing.exporter' value 'zipkin' is not supported", initTracing(cfg).Error())
+}
+
+func TestTracingStdoutExporter(t *testing.T) {
+	buf := &bytes.Buffer{}
+	exporter := NewStdoutSpanExporter(buf)
+	exporter.ExportSpan(&Span{TraceID: "4bf92f3577b34da6a3ce929d0e0e4736", SpanID: "00f067aa0ba902b7", Name: "render"})
+	exporter.ExportSpan(&Span{Name: "resolveView", Attributes: map[string]string{"view": "index.html"}})
+
+	lines := bytes.Split(bytes.TrimSpace(buf.Bytes()), []byte("\n"))
+	assert.Equal(t, 2, len(lines))
+
+	var span Span
+	assert.Nil(t, json.Unmarshal(lines[0], &span))
+	assert.Equal(t, "render", span.Name)
+	assert.Equal(t, "00f067aa0ba902b7", span.SpanID)
+	assert.Nil(t, json.Unmarshal(lines[1], &span))
+	assert.Equal(t, "index.html", span.Attributes["view"])
+}
+
+func TestTracingSpans(t *testing.T) {
+	exporter := NewInMemorySpanExporter()
+	appTracingEnabled, appSpanExporter = true, exporter
+	defer func() { appTracingEnabled, appSpanExporter = false, nil }()
+
+	oldStack := mwStack
+	mwStack = []MiddlewareFunc{testTraceMiddleware, interceptorMiddleware, actionMiddleware}
+	invalidateMwChain()
+	defer func() {
+		mwStack = oldStack
+		invalidateMwChain()
+	}()
+
+	appConfig, _ = config.ParseString("")
+	err := initSecurity(filepath.Join(getTestdataPath(), appConfigDir()), appConfig)
+	assert.FailNowOnError(t, err, "")
+	e := newEngine(appConfig)
+
+	r := httptest.NewRequest("GET", "http://localhost:8080/credits", nil)
+	r.Header.Set(HeaderTraceparent, "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-00")
+	ctx := e.prepareContext(httptest.NewRecorder(), r)
+	ctx.route = &router.Route{Name: "credits"}
+	ctx.controller, ctx.action = "Site", &MethodInfo{Name: "Credits"}
+	ctx.target = &Site{Context: ctx}
+
+	startRequestSpan(ctx)
+	root := ctx.Span()
+	e.executeMiddlewares(ctx)
+	e.writeReply(ctx)
+	assert.Equal(t, root, ctx.Span())
+
+	hdr := http.Header{}
+	ctx.InjectTraceparent(hdr)
+	assert.Equal(t, "00-4bf92f3577b34da6a3ce929d0e0e4736-"+root.SpanID+"-00", hdr.Get(HeaderTraceparent))
+	endRequestSpan(ctx)
+
+	spans := map[string]*Span{}
+	var names []string
+	for _, span := range exporter.Spans() {
+		assert.Equal(t, "4bf92f3577b34da6a3ce929d0e0e4736", span.TraceID)
+		assert.False(t, span.EndTime.IsZero())
+		spans[span.Name] = span
+		names = append(names, span.Name)
+	}
+
+	assert.Equal(t, []string{
+		"db.query",
+		"interceptor Before",
+		"action Site.Credits",
+		"middleware actionMiddleware",
+		"interceptor After",
+		"middleware interceptorMiddleware",
+		"middleware testTraceMiddleware",
+		"resolveView",
+		"render",
+		"write",
+		"ServeHTTP",
+	}, names)
+
+	assert.Equal(t, "00f067aa0ba902b7", spans["ServeHTTP"].ParentID)
+	assert.Equal(t, "credits", spans["ServeHTTP"].Attributes["aah.route"])
+	assert.Equal(t, "200", spans["ServeHTTP"].Attributes["http.status_code"])
+	assert.Equal(t, "GET", spans["ServeHTTP"].Attributes["http.method"])
+	assert.Equal(t, root.SpanID, spans["middleware testTraceMiddleware"].ParentID)
+	assert.Equal(t, spans["middleware testTraceMiddleware"].SpanID, spans["db.query"].ParentID)
+	assert.Equal(t, "credits", spans["db.query"].Attributes["db.table"])
+	assert.Equal(t, spans["middleware actionMiddleware"].SpanID, spans["action Site.Credits"].ParentID)
+	assert.Equal(t, spans["middleware interceptorMiddleware"].SpanID, spans["interceptor Before"].ParentID)
+	assert.Equal(t, root.SpanID, spans["render"].ParentID)
+
+	// tracing disabled
+	exporter.Reset()
+	appTracingEnabled = false
+	ctx = e.prepareContext(httptest.NewRecorder(), httptest.NewRequest("GET", "http://localhost:8080/credits", nil))
+	startRequestSpan(ctx)
+	assert.Nil(t, ctx.Span())
+	assert.Nil(t, ctx.StartSpan("db.query"))
+	ctx.InjectTraceparent(hdr)
+	endRequestSpan(ctx)
+	assert.Equal(t, 0, len(exporter.Spans()))
+}
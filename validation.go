@@ -0,0 +1,175 @@
+// Copyright (c) Jeevanandam M. (https://github.com/jeevatkm)
+// go-aah/aah source code and usage is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package aah
+
+import (
+	"fmt"
+	"net/http"
+	"reflect"
+	"regexp"
+	"strings"
+	"sync"
+
+	"gopkg.in/go-playground/validator.v9"
+
+	"aahframework.org/ahttp.v0"
+	"aahframework.org/essentials.v0"
+	"aahframework.org/log.v0"
+)
+
+const keyValidationErrors = "ValidationErrors"
+
+var (
+	appValidator = newValidator()
+	regexpCache  = &struct {
+		sync.RWMutex
+		m map[string]*regexp.Regexp
+	}{m: make(map[string]*regexp.Regexp)}
+)
+
+type (
+	// ValidationError holds the details of single field validation failure.
+	// Message is resolved from i18n messages by key `validation.<tag>` with
+	// arguments field name and tag param.
+	ValidationError struct {
+		Field   string      `json:"field" xml:"field"`
+		Tag     string      `json:"tag" xml:"tag"`
+		Param   string      `json:"param,omitempty" xml:"param,omitempty"`
+		Value   interface{} `json:"-" xml:"-"`
+		Message string      `json:"message" xml:"message"`
+	}
+)
+
+//‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾
+// Global methods
+//___________________________________
+
+// Validator method returns the validator instance used by aah framework to
+// validate the bound action parameters. Use it to register your custom
+// validations, aliases, etc.
+//
+// Framework adds validation `regexp` in addition to built-in validations.
+// For e.g.: `validate:"regexp=^[a-z]+$"`, use `0x2C` for comma in the pattern.
+func Validator() *validator.Validate {
+	return appValidator
+}
+
+// Validate method validates the given struct based on `validate` tag and
+// returns `validator.ValidationErrors` if validation fails otherwise nil.
+func Validate(s interface{}) error {
+	return appValidator.Struct(s)
+}
+
+//‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾
+// Unexported methods
+//___________________________________
+
+func newValidator() *validator.Validate {
+	v := validator.New()
+	if err := v.RegisterValidation("regexp", validateRegexp); err != nil {
+		log.Error(err)
+	}
+	return v
+}
+
+// validateRegexp method validates field string value with given regexp
+// pattern.
+func validateRegexp(fl validator.FieldLevel) bool {
+	pattern := fl.Param()
+
+	regexpCache.RLock()
+	re, found := regexpCache.m[pattern]
+	regexpCache.RUnlock()
+
+	if !found {
+		var err error
+		if re, err = regexp.Compile(pattern); err != nil {
+			log.Errorf("validation: invalid regexp pattern '%s': %s", pattern, err)
+			return false
+		}
+
+		regexpCache.Lock()
+		regexpCache.m[pattern] = re
+		regexpCache.Unlock()
+	}
+
+	return re.MatchString(fl.Field().String())
+}
+
+// validateParameters method validates the bound struct values of action
+// parameters and returns the validation errors.
+func validateParameters(ctx *Context, values []reflect.Value) []*ValidationError {
+	var errs []*ValidationError
+	for _, value := range values {
+		if !value.IsValid() || !isStructType(value.Type()) ||
+			(value.Kind() == reflect.Ptr && value.IsNil()) {
+			continue
+		}
+
+		err := appValidator.Struct(value.Interface())
+		if err == nil {
+			continue
+		}
+
+		fieldErrs, ok := err.(validator.ValidationErrors)
+		if !ok {
+			log.Error(err)
+			continue
+		}
+
+		for _, fe := range fieldErrs {
+			errs = append(errs, newValidationError(ctx, fe))
+		}
+	}
+
+	return errs
+}
+
+func newValidationError(ctx *Context, fe validator.FieldError) *ValidationError {
+	// Namespace without top level struct name. For e.g.: `Address.City`
+	field := fe.Namespace()
+	if idx := strings.IndexByte(field, '.'); idx > 0 {
+		field = field[idx+1:]
+	}
+
+	return &ValidationError{
+		Field:   field,
+		Tag:     fe.Tag(),
+		Param:   fe.Param(),
+		Value:   fe.Value(),
+		Message: validationMessage(ctx.Req.Locale, field, fe.Tag(), fe.Param()),
+	}
+}
+
+// validationMessage method returns the i18n message for the given validation
+// tag otherwise default message.
+func validationMessage(locale *ahttp.Locale, field, tag, param string) string {
+	if AppI18n() != nil {
+		if msg := AppI18n().Lookup(locale, "validation."+tag, field, param); !ess.IsStrEmpty(msg) {
+			return msg
+		}
+	}
+
+	if ess.IsStrEmpty(param) {
+		return fmt.Sprintf("%s failed on the '%s' validation", field, tag)
+	}
+	return fmt.Sprintf("%s failed on the '%s=%s' validation", field, tag, param)
+}
+
+// isAutoRejectValidation method returns true if validation errors to be
+// replied by framework, it can be disabled per route via
+// `validation.auto_reject = false` otherwise as per aah.conf
+// `request.validation.auto_reject`.
+func isAutoRejectValidation(ctx *Context) bool {
+	return routeBoolDefault(ctx, "validation.auto_reject",
+		AppConfig().BoolDefault("request.validation.auto_reject", true))
+}
+
+// replyValidationErrors method replies HTTP Bad Request (Status 400) with
+// validation errors via error handler, `HTTPError.Data` holds the
+// validation errors.
+func replyValidationErrors(ctx *Context) {
+	handleError(ctx, &HTTPError{Code: http.StatusBadRequest, Data: ctx.validationErrors})
+}
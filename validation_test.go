@@ -0,0 +1,169 @@
+// Copyright (c) Jeevanandam M. (https://github.com/jeevatkm)
+// go-aah/aah source code and usage is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package aah
+
+import (
+	"bytes"
+	"net/http/httptest"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"aahframework.org/ahttp.v0"
+	"aahframework.org/config.v0"
+	"aahframework.org/router.v0"
+	"aahframework.org/test.v0/assert"
+	"aahframework.org/view.v0"
+)
+
+type (
+	validateAddress struct {
+		City    string `bind:"city" validate:"required"`
+		ZipCode string `bind:"zip" validate:"regexp=^[0-9]{6}$"`
+	}
+
+	validateUser struct {
+		Name    string           `bind:"name" validate:"required,min=3,max=20"`
+		Email   string           `bind:"email" validate:"required,email"`
+		Role    string           `bind:"role" validate:"oneof=admin user"`
+		Tags    []string         `bind:"tags" validate:"dive,min=2"`
+		Address *validateAddress `bind:"address" validate:"required"`
+	}
+
+	Registration struct {
+		*Context
+	}
+)
+
+func (r *Registration) Register(user *validateUser) {
+	r.Reply().Text("registered: %v, errors: %d", user.Name, len(r.ValidationErrors()))
+}
+
+func TestValidationValidate(t *testing.T) {
+	appConfig, _ = config.ParseString("")
+	appI18n = nil
+
+	user := &validateUser{
+		Name:    "John",
+		Email:   "john@example.com",
+		Role:    "user",
+		Tags:    []string{"go", "aah"},
+		Address: &validateAddress{City: "Chennai", ZipCode: "600001"},
+	}
+	assert.Nil(t, Validate(user))
+	assert.NotNil(t, Validator())
+
+	user.Address.ZipCode = "6000"
+	user.Email = "john"
+	req := httptest.NewRequest("POST", "http://localhost:8080/register", nil)
+	ctx := &Context{Req: ahttp.ParseRequest(req, &ahttp.Request{})}
+	ctx.Req.Locale = ahttp.NewLocale("en")
+
+	errs := validateParameters(ctx, []reflect.Value{reflect.ValueOf(user), reflect.ValueOf(10)})
+	assert.Equal(t, 2, len(errs))
+	assert.Equal(t, "Email", errs[0].Field)
+	assert.Equal(t, "email", errs[0].Tag)
+	assert.Equal(t, "Email failed on the 'email' validation", errs[0].Message)
+	assert.Equal(t, "Address.ZipCode", errs[1].Field)
+	assert.Equal(t, "regexp", errs[1].Tag)
+	assert.Equal(t, "Address.ZipCode failed on the 'regexp=^[0-9]{6}$' validation", errs[1].Message)
+
+	// nil pointer skipped
+	errs = validateParameters(ctx, []reflect.Value{reflect.ValueOf((*validateUser)(nil))})
+	assert.Nil(t, errs)
+}
+
+func TestValidationMessageI18n(t *testing.T) {
+	i18nDir := filepath.Join(getTestdataPath(), appI18nDir())
+	err := initI18n(i18nDir)
+	assert.FailNowOnError(t, err, "")
+
+	msg := validationMessage(ahttp.NewLocale("en"), "Email", "required", "")
+	assert.Equal(t, "Email is required", msg)
+
+	msg = validationMessage(ahttp.NewLocale("en"), "Name", "min", "3")
+	assert.Equal(t, "Name failed on the 'min=3' validation", msg)
+
+	appI18n = nil
+}
+
+func TestValidationActionMiddleware(t *testing.T) {
+	appConfig, _ = config.ParseString("")
+	appI18n = nil
+
+	cRegistry = controllerRegistry{}
+	AddController((*Registration)(nil), []*MethodInfo{
+		{
+			Name: "Register",
+			Parameters: []*ParameterInfo{
+				{Name: "user", Type: reflect.TypeOf((*validateUser)(nil))},
+			},
+		},
+	})
+
+	newCtx := func(accept string) *Context {
+		req := httptest.NewRequest("GET", "http://localhost:8080/register?name=Jo&email=jo@example.com&role=guest", nil)
+		req.Header.Set(ahttp.HeaderAccept, accept)
+		ctx := &Context{
+			Req:      ahttp.ParseRequest(req, &ahttp.Request{}),
+			reply:    NewReply(),
+			viewArgs: make(map[string]interface{}),
+		}
+		ctx.Req.Locale = ahttp.NewLocale("en")
+		ctx.action = cRegistry.Lookup(&router.Route{Controller: "Registration"}).FindMethod("Register")
+		ctx.target = &Registration{Context: ctx}
+		return ctx
+	}
+
+	// JSON reply
+	ctx := newCtx(ahttp.ContentTypeJSON.Raw())
+	actionMiddleware(ctx, &Middleware{})
+	assert.Equal(t, 400, ctx.Reply().Code)
+	assert.Equal(t, 3, len(ctx.ValidationErrors()))
+
+	// address params are not present, it's left nil
+	assert.Equal(t, "Address", ctx.ValidationErrors()[2].Field)
+	assert.Equal(t, "required", ctx.ValidationErrors()[2].Tag)
+
+	buf := &bytes.Buffer{}
+	err := ctx.Reply().Rdr.Render(buf)
+	assert.Nil(t, err)
+	assert.True(t, strings.HasPrefix(buf.String(), `{"code":400,"message":"Bad Request","data":[{"field":"Name","tag":"min","param":"3"`))
+
+	// XML reply
+	ctx = newCtx(ahttp.ContentTypeXML.Raw())
+	actionMiddleware(ctx, &Middleware{})
+	buf.Reset()
+	err = ctx.Reply().Rdr.Render(buf)
+	assert.Nil(t, err)
+	assert.True(t, strings.HasPrefix(buf.String(), `<error><code>400</code><message>Bad Request</message><data><field>Name</field>`))
+
+	// HTML reply
+	appViewEngine = &view.GoViewEngine{}
+	ctx = newCtx(ahttp.ContentTypeHTML.Raw())
+	actionMiddleware(ctx, &Middleware{})
+	buf.Reset()
+	err = ctx.Reply().Rdr.Render(buf)
+	assert.Nil(t, err)
+	assert.True(t, strings.Contains(buf.String(), "<li>Role failed on the &#39;oneof=admin user&#39; validation</li>"))
+	appViewEngine = nil
+
+	// Text reply
+	ctx = newCtx(ahttp.ContentTypePlainText.Raw())
+	actionMiddleware(ctx, &Middleware{})
+	assert.True(t, strings.HasPrefix(ctx.Reply().Rdr.(*Text).Format, "400 Bad Request\nName failed on the 'min=3' validation"))
+
+	// opt-out of auto reject
+	AppConfig().SetBool("request.validation.auto_reject", false)
+	ctx = newCtx(ahttp.ContentTypeJSON.Raw())
+	actionMiddleware(ctx, &Middleware{})
+	assert.Equal(t, 200, ctx.Reply().Code)
+	assert.Equal(t, []interface{}{"Jo", 3}, ctx.Reply().Rdr.(*Text).Values)
+	assert.NotNil(t, ctx.ViewArgs()[keyValidationErrors])
+
+	ctx.Reset()
+	assert.Nil(t, ctx.ValidationErrors())
+}
@@ -76,28 +76,40 @@ func initViewEngine(viewDir string, appCfg *config.Config) error {
 		}
 
 		appViewEngine = viewEngine
-		return appViewEngine.Init(appCfg, viewDir)
+		if err := appViewEngine.Init(appCfg, viewDir); err != nil {
+			return err
+		}
+	} else {
+		isExternalTmplEngine = true
 	}
 
-	isExternalTmplEngine = true
-	return nil
+	// HTTP error templates
+	return initErrorTemplates(filepath.Join(viewDir, "errors"))
 }
 
 // resolveView method does -
 //   1) Prepare ViewArgs
 //   2) If HTML content type find appropriate template
 func (e *engine) resolveView(ctx *Context) {
+	defer pipelineStage(ctx, "resolveView", stageRender)()
+
 	reply := ctx.Reply()
 
 	// HTML response
-	if ahttp.ContentTypeHTML.IsEqual(reply.ContType) && appViewEngine != nil {
-		if reply.Rdr == nil {
+	if ahttp.ContentTypeHTML.IsEqual(reply.ContType) {
+		if reply.Rdr == nil && appViewEngine != nil {
 			reply.Rdr = &HTML{}
 		}
 
-		htmlRdr := reply.Rdr.(*HTML)
+		// template could be already resolved, for e.g.: framework error
+		// template, it gets ViewArgs without the template lookup
+		htmlRdr, ok := reply.Rdr.(*HTML)
+		if !ok || (htmlRdr.Template == nil && appViewEngine == nil) {
+			return
+		}
 
-		if ess.IsStrEmpty(htmlRdr.Layout) {
+		if ess.IsStrEmpty(htmlRdr.Layout) &&
+			(htmlRdr.Template == nil || htmlRdr.Template.Lookup(appDefaultTmplLayout) != nil) {
 			htmlRdr.Layout = appDefaultTmplLayout
 		}
 
@@ -127,7 +139,9 @@ func (e *engine) resolveView(ctx *Context) {
 		htmlRdr.ViewArgs["AppBuildInfo"] = AppBuildInfo()
 
 		// find view template by convention if not provided
-		findViewTemplate(ctx)
+		if htmlRdr.Template == nil {
+			findViewTemplate(ctx)
+		}
 	}
 }
 
@@ -155,10 +169,19 @@ func findViewTemplate(ctx *Context) {
 	}
 
 	tmplPath := filepath.Join("pages", controllerName)
-	tmplName := ctx.action.Name + appViewExt
 	htmlRdr := ctx.Reply().Rdr.(*HTML)
-	if !ess.IsStrEmpty(htmlRdr.Filename) {
-		tmplName = htmlRdr.Filename
+	tmplName := htmlRdr.Filename
+	if ess.IsStrEmpty(tmplName) {
+		// action is not available for e.g.: route not found, method not allowed
+		if ctx.action == nil {
+			tmplFile := filepath.Join("views", tmplPath)
+			log.Errorf("template filename is not provided for %s: %s", ctx.Req.Path, tmplFile)
+			htmlRdr.ViewArgs["ViewNotFound"] = tmplFile
+			htmlRdr.Layout = ""
+			htmlRdr.Template = viewNotFoundTemplate
+			return
+		}
+		tmplName = ctx.action.Name + appViewExt
 	}
 
 	log.Tracef("Layout: %s, Template Path: %s, Template Name: %s", htmlRdr.Layout, tmplPath, tmplName)
@@ -156,6 +156,14 @@ func TestViewResolveViewNotFound(t *testing.T) {
 	htmlRdr := ctx.Reply().Rdr.(*HTML)
 	assert.NotNil(t, htmlRdr.Template)
 
+	// no action and filename, for e.g.: route not found
+	ctx.action = nil
+	ctx.Reply().Rdr = nil
+	e.resolveView(ctx)
+	htmlRdr = ctx.Reply().Rdr.(*HTML)
+	assert.Equal(t, viewNotFoundTemplate, htmlRdr.Template)
+	assert.Equal(t, filepath.Join("views", "pages", "App"), htmlRdr.ViewArgs["ViewNotFound"])
+
 	// cleanup
 	appViewEngine = nil
 }